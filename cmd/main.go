@@ -29,6 +29,14 @@ func main() {
 	handler := api.NewHandler(anthropicService)
 
 	http.HandleFunc("/v1/cron", handler.HandleCronRequest)
+	http.HandleFunc("/v1/compare", handler.HandleCompareRequest)
+	http.HandleFunc("/v1/systemd", handler.HandleSystemdRequest)
+	http.HandleFunc("/v1/rrule", handler.HandleRRuleRequest)
+	http.HandleFunc("/v1/calendar.ics", handler.HandleCalendarRequest)
+	http.HandleFunc("/v1/occurrences", handler.HandleOccurrencesRequest)
+	http.HandleFunc("/v1/stats", handler.HandleStatsRequest)
+	http.HandleFunc("/v1/analyze", handler.HandleAnalyzeRequest)
+	http.HandleFunc("/v1/stagger", handler.HandleStaggerRequest)
 
 	log.Printf("Starting server on :%s", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
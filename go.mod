@@ -2,7 +2,4 @@ module github.com/abhikvarma/crontalk
 
 go 1.20
 
-require (
-	github.com/joho/godotenv v1.5.1
-	github.com/robfig/cron/v3 v3.0.1
-)
+require github.com/joho/godotenv v1.5.1
@@ -39,12 +39,24 @@ const (
 / (forward slash): Step values
 ? (question mark): Non-specific value (for Day of the week or Day of the month)
 L: Last day of the month or week
+LW: Last weekday of the month (used with Day of the month)
+L-n: n days before the last day of the month, e.g. L-3 (used with Day of the month)
+nL: Last given weekday of the month, e.g. 5L for the last Friday (used with Day of the week)
 W: Nearest weekday (used with Day of the month)
 #: Weekday of the month (used with Day of the week)
 
-5. Validate the generated cron expression to ensure it's correct and achievable.
+5. When the request maps exactly onto one of these predefined macros, you may return the macro instead of the 5 fields:
+@yearly (or @annually): Once a year at midnight on January 1st, same as 0 0 1 1 *
+@monthly: Once a month at midnight on the 1st, same as 0 0 1 * *
+@weekly: Once a week at midnight on Sunday, same as 0 0 * * 0
+@daily (or @midnight): Once a day at midnight, same as 0 0 * * *
+@hourly: At the start of every hour, same as 0 * * * *
+@reboot: Once at startup
+@every <duration>: At a fixed interval from when the scheduler starts, e.g. @every 1h30m
 
-6. Format the output as a JSON object with the following structure:
+6. Validate the generated cron expression to ensure it's correct and achievable.
+
+7. Format the output as a JSON object with the following structure:
 {
 "cron": "<generated_cron_expression>",
 "error": "<error_message>"
@@ -65,6 +77,9 @@ Output: {"cron": "*/15 * * * *", "error": ""}
 Request: "Run at 2:30 PM on weekdays"
 Output: {"cron": "30 14 * * 1-5", "error": ""}
 
+Request: "Run once when the server starts"
+Output: {"cron": "@reboot", "error": ""}
+
 Here are some examples of invalid requests and their corresponding outputs:
 
 Request: "Run on February 30th"
@@ -118,7 +133,14 @@ type LlmCronResponse struct {
 	Error string `json:"error"`
 }
 
-func (c *Client) CompletePromptJson(ctx context.Context, userRequest string) (LlmCronResponse, error) {
+// CompletePromptJson asks the model for a cron expression. Instructions, if
+// set, are appended to the system prompt, e.g. to target a cron dialect.
+func (c *Client) CompletePromptJson(ctx context.Context, userRequest, instructions string) (LlmCronResponse, error) {
+	system := systemPrompt
+	if instructions != "" {
+		system += "\n\nThese instructions take precedence over the format described above:\n" + instructions
+	}
+
 	messages := []Message{
 		{Role: "user", Content: userRequest},
 		{Role: "assistant", Content: "{"},
@@ -129,7 +151,7 @@ func (c *Client) CompletePromptJson(ctx context.Context, userRequest string) (Ll
 		Messages:    messages,
 		MaxTokens:   300,
 		Temperature: 0.25,
-		System:      systemPrompt,
+		System:      system,
 	})
 	if err != nil {
 		return LlmCronResponse{}, fmt.Errorf("failed to marshal request: %w", err)
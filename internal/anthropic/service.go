@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
 )
 
 type Service struct {
@@ -16,8 +18,10 @@ func NewService(apiKey, model string) *Service {
 	}
 }
 
-func (s *Service) ProcessCronQuestion(ctx context.Context, input string) (LlmCronResponse, error) {
-	cronResp, err := s.client.CompletePromptJson(ctx, input)
+// ProcessCronQuestion generates a cron expression for input, written for
+// the given dialect.
+func (s *Service) ProcessCronQuestion(ctx context.Context, input string, dialect *cron_internal.Dialect) (LlmCronResponse, error) {
+	cronResp, err := s.client.CompletePromptJson(ctx, input, dialect.Prompt)
 	if err != nil {
 		return LlmCronResponse{}, fmt.Errorf("failed to process cron question: %w", err)
 	}
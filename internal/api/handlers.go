@@ -2,26 +2,60 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/abhikvarma/crontalk/internal/anthropic"
 	"github.com/abhikvarma/crontalk/internal/cron_internal"
 	"github.com/abhikvarma/crontalk/pkg/cronutil"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 type Handler struct {
 	anthropicService *anthropic.Service
+	// clock tells the time run times are computed from.
+	clock cronutil.Clock
 }
 
 func NewHandler(as *anthropic.Service) *Handler {
-	return &Handler{anthropicService: as}
+	return &Handler{anthropicService: as, clock: cronutil.SystemClock}
 }
 
+// maxHorizonDays bounds the horizons, in days, that requests look ahead.
+const maxHorizonDays = 3650
+
+// statsSummaryDays is the horizon of the statistics in a cron response.
+const statsSummaryDays = 30
+
 type CronResponse struct {
-	CronExpression string   `json:"cron_expression,omitempty"`
-	NextRunTimes   []string `json:"next_run_times,omitempty"`
-	ErrorMessage   string   `json:"error_message,omitempty"`
+	CronExpression string `json:"cron_expression,omitempty"`
+	// ResolvedExpression is CronExpression with Jenkins' H resolved for the
+	// request's seed. It is only set when CronExpression uses H, and the
+	// description and run times are then for the resolved form.
+	ResolvedExpression string `json:"resolved_expression,omitempty"`
+	Description        string `json:"description,omitempty"`
+	// NextRunTimes are in TimeZone, the zone the expression runs in, and
+	// NextRunTimesUTC are the same times in UTC. TimeZone is empty when
+	// neither the expression nor the request names a zone, and the server's
+	// local zone is used.
+	NextRunTimes    []string `json:"next_run_times,omitempty"`
+	NextRunTimesUTC []string `json:"next_run_times_utc,omitempty"`
+	TimeZone        string   `json:"time_zone,omitempty"`
+	NoRunTimes      bool     `json:"no_run_times,omitempty"`
+	// DSTReport lists the runs within the request's DST horizon that a
+	// daylight saving transition skips, duplicates or shifts.
+	DSTReport []cron_internal.DSTOccurrence `json:"dst_report,omitempty"`
+	// Stats summarizes how often the schedule runs over the next
+	// statsSummaryDays; /v1/stats has the full data.
+	Stats        *cronutil.StatsSummary `json:"stats,omitempty"`
+	ErrorMessage string                 `json:"error_message,omitempty"`
+	// Diagnostics lists every problem found in an invalid expression, or
+	// warnings and lint findings with suggested fixes for a valid one, and
+	// DiagnosticsReport renders them with carets under the offending text.
+	Diagnostics       []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+	DiagnosticsReport string                     `json:"diagnostics_report,omitempty"`
 }
 
 func (h *Handler) HandleCronRequest(w http.ResponseWriter, r *http.Request) {
@@ -32,6 +66,20 @@ func (h *Handler) HandleCronRequest(w http.ResponseWriter, r *http.Request) {
 
 	var input struct {
 		CronQuestion string `json:"cron_question"`
+		// Locale selects the description language, e.g. "de" or "pt-BR".
+		// The Accept-Language header is used when it is empty.
+		Locale string `json:"locale"`
+		// Dialect selects the scheduler the expression is written for, e.g.
+		// "quartz" or "kubernetes". It defaults to "standard".
+		Dialect string `json:"dialect"`
+		// Seed, such as a job name, resolves Jenkins' H to concrete values.
+		Seed string `json:"seed"`
+		// Timezone is the IANA time zone the schedule runs in, e.g.
+		// "Asia/Kolkata", unless the expression has a CRON_TZ= prefix.
+		Timezone string `json:"timezone"`
+		// DSTHorizonDays is how far ahead to look for daylight saving
+		// transitions affecting the schedule. It defaults to a year.
+		DSTHorizonDays int `json:"dst_horizon_days"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -39,7 +87,27 @@ func (h *Handler) HandleCronRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	llmCronResp, err := h.anthropicService.ProcessCronQuestion(r.Context(), input.CronQuestion)
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		createJsonResponse(w, CronResponse{ErrorMessage: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if input.DSTHorizonDays < 0 || input.DSTHorizonDays > maxHorizonDays {
+		createJsonResponse(w, CronResponse{ErrorMessage: fmt.Sprintf("dst_horizon_days must be between 0 and %d", maxHorizonDays)}, http.StatusBadRequest)
+		return
+	}
+	if input.DSTHorizonDays == 0 {
+		input.DSTHorizonDays = 365
+	}
+	var loc *time.Location
+	if input.Timezone != "" {
+		if loc, err = time.LoadLocation(input.Timezone); err != nil {
+			createJsonResponse(w, CronResponse{ErrorMessage: fmt.Sprintf("unknown time zone %q", input.Timezone)}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	llmCronResp, err := h.anthropicService.ProcessCronQuestion(r.Context(), input.CronQuestion, dialect)
 	if err != nil {
 		log.Printf("Error processing cron question: %v", err)
 		http.Error(w, "Error processing cron questions", http.StatusInternalServerError)
@@ -54,21 +122,716 @@ func (h *Handler) HandleCronRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// todo: add a flow to fix using LLMs here, can loop in the users as well
-	if err := cron_internal.ValidateCron(llmCronResp.Cron); err != nil {
+	cronExp, err := cron_internal.ParseCronDialect(llmCronResp.Cron, dialect)
+	if err == nil {
+		err = cronExp.Validate()
+	}
+	if err != nil {
 		response.ErrorMessage = ":( Invalid cron expression generated: " + llmCronResp.Cron
+		response.Diagnostics = cron_internal.DiagnoseDialect(llmCronResp.Cron, dialect)
+		response.DiagnosticsReport = cron_internal.RenderDiagnostics(llmCronResp.Cron, response.Diagnostics)
 		createJsonResponse(w, response, http.StatusOK)
 		return
 	}
 
-	response.CronExpression = llmCronResp.Cron
-	nextRunTimes, err := cronutil.GetNextRunTimes(llmCronResp.Cron, 5)
-	if err != nil {
+	feasibility := cron_internal.DiagnoseFeasibility(llmCronResp.Cron, dialect)
+	if len(feasibility) > 0 && feasibility[0].Severity == cron_internal.SeverityError {
+		response.ErrorMessage = ":( Generated cron expression never runs: " + llmCronResp.Cron
+		response.Diagnostics = feasibility
+		response.DiagnosticsReport = cron_internal.RenderDiagnostics(llmCronResp.Cron, feasibility)
+		createJsonResponse(w, response, http.StatusOK)
+		return
+	}
+	response.Diagnostics = append(feasibility, cron_internal.LintCron(llmCronResp.Cron, dialect)...)
+	if len(response.Diagnostics) > 0 {
+		response.DiagnosticsReport = cron_internal.RenderDiagnostics(llmCronResp.Cron, response.Diagnostics)
+	}
+
+	cronExp = cronExp.Normalize(cron_internal.NormalizeOptions{})
+	cronExpression := cronExp.String()
+	response.CronExpression = cronExpression
+	if cronExp.IsHashed() {
+		cronExp = cronExp.Resolve(input.Seed).Normalize(cron_internal.NormalizeOptions{})
+		cronExpression = cronExp.String()
+		response.ResolvedExpression = cronExpression
+	}
+	locale := input.Locale
+	if locale == "" {
+		locale = r.Header.Get("Accept-Language")
+	}
+	response.Description = cronExp.DescribeIn(locale)
+	if cronExp.Location != nil {
+		loc = cronExp.Location
+	}
+	if loc != nil {
+		response.TimeZone = loc.String()
+	}
+	nextRunTimes, err := cronutil.GetNextRunTimesWithClock(cronExpression, dialect, loc, h.clock, 5)
+	if errors.Is(err, cronutil.ErrNoRunTimes) {
+		response.NoRunTimes = true
+	} else if err != nil {
 		log.Printf("Failed to calculate next run times for cron %s with error %v", llmCronResp, err)
 	} else {
 		response.NextRunTimes = make([]string, len(nextRunTimes))
+		response.NextRunTimesUTC = make([]string, len(nextRunTimes))
 		for i, t := range nextRunTimes {
 			response.NextRunTimes[i] = t.Format(time.RFC3339)
+			response.NextRunTimesUTC[i] = t.UTC().Format(time.RFC3339)
+		}
+	}
+	now := h.clock.Now()
+	if loc != nil {
+		now = now.In(loc)
+	}
+	response.DSTReport = cronExp.DSTReport(now, time.Duration(input.DSTHorizonDays)*24*time.Hour)
+	if stats, err := cronutil.Analyze(cronExpression, dialect, loc, now, statsSummaryDays*24*time.Hour); err != nil {
+		log.Printf("Failed to analyze cron %s with error %v", cronExpression, err)
+	} else {
+		response.Stats = &stats.StatsSummary
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+type CompareResponse struct {
+	Equivalent bool `json:"equivalent"`
+	// Counterexample is a time at which only one of the expressions fires,
+	// named by FiredBy as "a" or "b". Like the comparison it is a wall clock
+	// time, written in UTC, and ignores time zone prefixes.
+	Counterexample string                     `json:"counterexample,omitempty"`
+	FiredBy        string                     `json:"fired_by,omitempty"`
+	ErrorMessage   string                     `json:"error_message,omitempty"`
+	Diagnostics    []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+func (h *Handler) HandleCompareRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input struct {
+		A       string `json:"a"`
+		B       string `json:"b"`
+		Dialect string `json:"dialect"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := CompareResponse{}
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	var parsed []*cron_internal.Expression
+	for _, expression := range []string{input.A, input.B} {
+		if diags := cron_internal.DiagnoseDialect(expression, dialect); len(diags) > 0 {
+			response.ErrorMessage = "Invalid cron expression: " + expression
+			response.Diagnostics = diags
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		cronExp, _ := cron_internal.ParseCronDialect(expression, dialect)
+		parsed = append(parsed, cronExp)
+	}
+
+	equivalent, counterexample := cron_internal.Equivalent(parsed[0], parsed[1])
+	response.Equivalent = equivalent
+	if !counterexample.IsZero() {
+		response.Counterexample = counterexample.Format(time.RFC3339)
+		// Equivalent compares wall clock times, so the counterexample is
+		// matched against a's fields without its time zone prefix.
+		a := *parsed[0]
+		a.Location = nil
+		response.FiredBy = "b"
+		if a.Matches(counterexample) {
+			response.FiredBy = "a"
+		}
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+type SystemdResponse struct {
+	CronExpression string                      `json:"cron_expression,omitempty"`
+	OnCalendar     string                      `json:"on_calendar,omitempty"`
+	Description    string                      `json:"description,omitempty"`
+	NextRunTimes   []string                    `json:"next_run_times,omitempty"`
+	Units          *cron_internal.SystemdUnits `json:"units,omitempty"`
+	ErrorMessage   string                      `json:"error_message,omitempty"`
+	Diagnostics    []cron_internal.Diagnostic  `json:"diagnostics,omitempty"`
+}
+
+// HandleSystemdRequest converts between a cron expression and a systemd
+// OnCalendar= event, previews the next run times and, when a command is
+// given, generates the .timer/.service unit pair.
+func (h *Handler) HandleSystemdRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input struct {
+		// Exactly one of CronExpression and OnCalendar is expected.
+		CronExpression string `json:"cron_expression"`
+		Dialect        string `json:"dialect"`
+		OnCalendar     string `json:"on_calendar"`
+		Name           string `json:"name"`
+		Description    string `json:"description"`
+		Command        string `json:"command"`
+		User           string `json:"user"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := SystemdResponse{}
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+
+	var cronExp *cron_internal.Expression
+	if input.OnCalendar != "" {
+		if cronExp, err = cron_internal.ParseOnCalendar(input.OnCalendar); err != nil {
+			response.ErrorMessage = err.Error()
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		response.OnCalendar = input.OnCalendar
+		response.CronExpression = cronExp.String()
+		if transpiled, err := cron_internal.Transpile(cronExp, dialect); err == nil && len(transpiled) == 1 {
+			response.CronExpression = transpiled[0].String()
+		}
+	} else {
+		if diags := cron_internal.DiagnoseDialect(input.CronExpression, dialect); len(diags) > 0 {
+			response.ErrorMessage = "Invalid cron expression: " + input.CronExpression
+			response.Diagnostics = diags
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		cronExp, _ = cron_internal.ParseCronDialect(input.CronExpression, dialect)
+		response.CronExpression = input.CronExpression
+		if response.OnCalendar, err = cronExp.OnCalendar(); err != nil {
+			response.ErrorMessage = err.Error()
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+
+	response.Description = cronExp.Describe()
+	nextRunTimes, err := cronutil.GetNextRunTimesWithClock(cronExp.String(), cronExp.Dialect, nil, h.clock, 5)
+	if err != nil && !errors.Is(err, cronutil.ErrNoRunTimes) {
+		log.Printf("Failed to calculate next run times for cron %s with error %v", cronExp, err)
+	}
+	for _, t := range nextRunTimes {
+		response.NextRunTimes = append(response.NextRunTimes, t.Format(time.RFC3339))
+	}
+
+	if input.Command != "" {
+		units, err := cronExp.SystemdUnits(cron_internal.SystemdUnitOptions{Name: input.Name, Description: input.Description, Command: input.Command, User: input.User})
+		if err != nil {
+			response.ErrorMessage = err.Error()
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		response.Units = &units
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+type RRuleResponse struct {
+	CronExpression string                     `json:"cron_expression,omitempty"`
+	RRules         []string                   `json:"rrules,omitempty"`
+	NextRunTimes   []string                   `json:"next_run_times,omitempty"`
+	ErrorMessage   string                     `json:"error_message,omitempty"`
+	Diagnostics    []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// HandleRRuleRequest converts a cron expression to iCalendar recurrence
+// rules, or an RRULE to a cron expression.
+func (h *Handler) HandleRRuleRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input struct {
+		// Exactly one of CronExpression and RRule is expected.
+		CronExpression string `json:"cron_expression"`
+		Dialect        string `json:"dialect"`
+		RRule          string `json:"rrule"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := RRuleResponse{}
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+
+	var cronExp *cron_internal.Expression
+	if input.RRule != "" {
+		if cronExp, err = cron_internal.ParseRRule(input.RRule); err != nil {
+			response.ErrorMessage = err.Error()
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		response.RRules = []string{input.RRule}
+		response.CronExpression = cronExp.String()
+		if transpiled, err := cron_internal.Transpile(cronExp, dialect); err == nil && len(transpiled) == 1 {
+			response.CronExpression = transpiled[0].String()
+		}
+	} else {
+		if diags := cron_internal.DiagnoseDialect(input.CronExpression, dialect); len(diags) > 0 {
+			response.ErrorMessage = "Invalid cron expression: " + input.CronExpression
+			response.Diagnostics = diags
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		cronExp, _ = cron_internal.ParseCronDialect(input.CronExpression, dialect)
+		response.CronExpression = input.CronExpression
+		if response.RRules, err = cronExp.RRules(); err != nil {
+			response.ErrorMessage = err.Error()
+		}
+	}
+
+	nextRunTimes, err := cronutil.GetNextRunTimesWithClock(cronExp.String(), cronExp.Dialect, nil, h.clock, 5)
+	if err != nil && !errors.Is(err, cronutil.ErrNoRunTimes) {
+		log.Printf("Failed to calculate next run times for cron %s with error %v", cronExp, err)
+	}
+	for _, t := range nextRunTimes {
+		response.NextRunTimes = append(response.NextRunTimes, t.Format(time.RFC3339))
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+// maxCalendarEvents caps the count accepted by HandleCalendarRequest.
+const maxCalendarEvents = 500
+
+// HandleCalendarRequest serves the next run times of a cron expression as
+// an iCalendar feed, e.g. GET /v1/calendar.ics?cron=0+9+*+*+1-5&count=20,
+// so that a schedule can be subscribed to from a calendar app.
+func (h *Handler) HandleCalendarRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dialect, err := cron_internal.LookupDialect(query.Get("dialect"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	count := 50
+	if c := query.Get("count"); c != "" {
+		if count, err = strconv.Atoi(c); err != nil || count < 1 || count > maxCalendarEvents {
+			http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxCalendarEvents), http.StatusBadRequest)
+			return
+		}
+	}
+
+	calendar, err := cronutil.ExportICSWithClock(query.Get("cron"), dialect, h.clock, count, query.Get("summary"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if _, err := w.Write([]byte(calendar)); err != nil {
+		log.Printf("Error writing calendar response: %v", err)
+	}
+}
+
+type OccurrencesResponse struct {
+	CronExpression string `json:"cron_expression,omitempty"`
+	// Occurrences are in TimeZone, nearest to the reference time first.
+	Occurrences []string `json:"occurrences"`
+	TimeZone    string   `json:"time_zone,omitempty"`
+	// Truncated is set when the window holds more than
+	// cronutil.MaxOccurrences runs and only the first ones are listed.
+	Truncated    bool                       `json:"truncated,omitempty"`
+	ErrorMessage string                     `json:"error_message,omitempty"`
+	Diagnostics  []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// HandleOccurrencesRequest lists the run times of a cron expression around
+// a reference time, e.g.
+// GET /v1/occurrences?cron=0+9+*+*+1-5&from=2024-03-01T00:00:00Z&to=2024-04-01T00:00:00Z.
+// from defaults to now; direction=backward lists previous runs. Without to
+// or count the next 5 runs are listed.
+func (h *Handler) HandleOccurrencesRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	expression := query.Get("cron")
+	response := OccurrencesResponse{CronExpression: expression}
+
+	dialect, err := cron_internal.LookupDialect(query.Get("dialect"))
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	q := cronutil.Query{Clock: h.clock}
+	if q.Direction, err = cronutil.ParseDirection(query.Get("direction")); err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if tz := query.Get("timezone"); tz != "" {
+		if q.Location, err = time.LoadLocation(tz); err != nil {
+			response.ErrorMessage = fmt.Sprintf("unknown time zone %q", tz)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := query.Get(param.name); v != "" {
+			if *param.t, err = time.Parse(time.RFC3339, v); err != nil {
+				response.ErrorMessage = fmt.Sprintf("%s must be an RFC 3339 time, e.g. 2024-03-01T09:00:00Z", param.name)
+				createJsonResponse(w, response, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if c := query.Get("count"); c != "" {
+		if q.Count, err = strconv.Atoi(c); err != nil || q.Count < 1 || q.Count > cronutil.MaxOccurrences {
+			response.ErrorMessage = fmt.Sprintf("count must be between 1 and %d", cronutil.MaxOccurrences)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	} else if q.To.IsZero() {
+		q.Count = 5
+	}
+
+	if diags := cron_internal.DiagnoseDialect(expression, dialect); len(diags) > 0 {
+		response.Diagnostics = diags
+		response.ErrorMessage = "Invalid cron expression: " + expression
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	times, truncated, err := cronutil.Occurrences(expression, dialect, q)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	response.Truncated = truncated
+	response.Occurrences = make([]string, len(times))
+	for i, t := range times {
+		response.Occurrences[i] = t.Format(time.RFC3339)
+	}
+	if len(times) > 0 {
+		response.TimeZone = times[0].Location().String()
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+type StatsResponse struct {
+	CronExpression string                     `json:"cron_expression,omitempty"`
+	Stats          *cronutil.Stats            `json:"stats,omitempty"`
+	ErrorMessage   string                     `json:"error_message,omitempty"`
+	Diagnostics    []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// HandleStatsRequest reports how often a cron expression runs, e.g.
+// GET /v1/stats?cron=*/20+9-17+*+*+1-5&horizon_days=90. The horizon starts
+// at from, or now, and defaults to 30 days.
+func (h *Handler) HandleStatsRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	expression := query.Get("cron")
+	response := StatsResponse{CronExpression: expression}
+
+	dialect, err := cron_internal.LookupDialect(query.Get("dialect"))
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	var loc *time.Location
+	if tz := query.Get("timezone"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			response.ErrorMessage = fmt.Sprintf("unknown time zone %q", tz)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+	from := h.clock.Now()
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			response.ErrorMessage = "from must be an RFC 3339 time, e.g. 2024-03-01T09:00:00Z"
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+	days := statsSummaryDays
+	if d := query.Get("horizon_days"); d != "" {
+		if days, err = strconv.Atoi(d); err != nil || days < 1 || days > maxHorizonDays {
+			response.ErrorMessage = fmt.Sprintf("horizon_days must be between 1 and %d", maxHorizonDays)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if diags := cron_internal.DiagnoseDialect(expression, dialect); len(diags) > 0 {
+		response.ErrorMessage = "Invalid cron expression: " + expression
+		response.Diagnostics = diags
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if response.Stats, err = cronutil.Analyze(expression, dialect, loc, from, time.Duration(days)*24*time.Hour); err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+// maxAnalyzedJobs and maxAnalyzeHorizonHours bound analyze and stagger
+// requests.
+const (
+	maxAnalyzedJobs        = 1000
+	maxAnalyzeHorizonHours = 31 * 24
+)
+
+type AnalyzeResponse struct {
+	Report       *cronutil.CollisionReport `json:"report,omitempty"`
+	ErrorMessage string                    `json:"error_message,omitempty"`
+	// Job names the job whose expression Diagnostics are about.
+	Job         string                     `json:"job,omitempty"`
+	Diagnostics []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// HandleAnalyzeRequest finds when a set of named cron jobs start or run
+// together, e.g. several jobs hitting a database at the top of the hour.
+func (h *Handler) HandleAnalyzeRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input struct {
+		Jobs []struct {
+			Name           string `json:"name"`
+			CronExpression string `json:"cron_expression"`
+			// Duration is how long each run takes, e.g. "15m".
+			Duration string `json:"duration"`
+		} `json:"jobs"`
+		Dialect  string `json:"dialect"`
+		Timezone string `json:"timezone"`
+		// From defaults to now and HorizonHours to a day.
+		From         string `json:"from"`
+		HorizonHours int    `json:"horizon_hours"`
+		// Threshold is how many jobs may run together before it is
+		// reported, by default 1. Windows is how many of the worst
+		// windows to list, by default 10.
+		Threshold int `json:"threshold"`
+		Windows   int `json:"windows"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := AnalyzeResponse{}
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if len(input.Jobs) == 0 || len(input.Jobs) > maxAnalyzedJobs {
+		response.ErrorMessage = fmt.Sprintf("between 1 and %d jobs are required", maxAnalyzedJobs)
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if input.HorizonHours < 0 || input.HorizonHours > maxAnalyzeHorizonHours {
+		response.ErrorMessage = fmt.Sprintf("horizon_hours must be between 0 and %d", maxAnalyzeHorizonHours)
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if input.HorizonHours == 0 {
+		input.HorizonHours = 24
+	}
+	opts := cronutil.CollisionOptions{
+		Dialect:   dialect,
+		From:      h.clock.Now(),
+		Horizon:   time.Duration(input.HorizonHours) * time.Hour,
+		Threshold: input.Threshold,
+		Windows:   input.Windows,
+	}
+	if input.Timezone != "" {
+		if opts.Location, err = time.LoadLocation(input.Timezone); err != nil {
+			response.ErrorMessage = fmt.Sprintf("unknown time zone %q", input.Timezone)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+	if input.From != "" {
+		if opts.From, err = time.Parse(time.RFC3339, input.From); err != nil {
+			response.ErrorMessage = "from must be an RFC 3339 time, e.g. 2024-03-01T09:00:00Z"
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+
+	jobs := make([]cronutil.Job, len(input.Jobs))
+	for i, job := range input.Jobs {
+		if diags := cron_internal.DiagnoseDialect(job.CronExpression, dialect); len(diags) > 0 {
+			response.ErrorMessage = fmt.Sprintf("Invalid cron expression for job %q: %s", job.Name, job.CronExpression)
+			response.Job = job.Name
+			response.Diagnostics = diags
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+		jobs[i] = cronutil.Job{Name: job.Name, Expression: job.CronExpression}
+		if job.Duration != "" {
+			if jobs[i].Duration, err = time.ParseDuration(job.Duration); err != nil {
+				response.ErrorMessage = fmt.Sprintf("invalid duration %q for job %q", job.Duration, job.Name)
+				createJsonResponse(w, response, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	if response.Report, err = cronutil.AnalyzeCollisions(jobs, opts); err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	createJsonResponse(w, response, http.StatusOK)
+}
+
+type StaggerResponse struct {
+	Plan         *cronutil.StaggerPlan `json:"plan,omitempty"`
+	ErrorMessage string                `json:"error_message,omitempty"`
+	// Job names the job whose expression Diagnostics are about.
+	Job         string                     `json:"job,omitempty"`
+	Diagnostics []cron_internal.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// HandleStaggerRequest proposes rewritten expressions for a set of named
+// cron jobs that spread their start times, with the concurrency before and
+// after.
+func (h *Handler) HandleStaggerRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input struct {
+		Jobs []struct {
+			Name           string `json:"name"`
+			CronExpression string `json:"cron_expression"`
+			// Duration is how long each run takes and Tolerance how much
+			// later it may start, e.g. "15m" and "59m".
+			Duration  string `json:"duration"`
+			Tolerance string `json:"tolerance"`
+		} `json:"jobs"`
+		Dialect  string `json:"dialect"`
+		Timezone string `json:"timezone"`
+		// From defaults to now and HorizonHours to a week.
+		From         string `json:"from"`
+		HorizonHours int    `json:"horizon_hours"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := StaggerResponse{}
+	dialect, err := cron_internal.LookupDialect(input.Dialect)
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if len(input.Jobs) == 0 || len(input.Jobs) > maxAnalyzedJobs {
+		response.ErrorMessage = fmt.Sprintf("between 1 and %d jobs are required", maxAnalyzedJobs)
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	if input.HorizonHours < 0 || input.HorizonHours > maxAnalyzeHorizonHours {
+		response.ErrorMessage = fmt.Sprintf("horizon_hours must be between 0 and %d", maxAnalyzeHorizonHours)
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
+	}
+	opts := cronutil.StaggerOptions{
+		Dialect: dialect,
+		From:    h.clock.Now(),
+		Horizon: time.Duration(input.HorizonHours) * time.Hour,
+	}
+	if input.Timezone != "" {
+		if opts.Location, err = time.LoadLocation(input.Timezone); err != nil {
+			response.ErrorMessage = fmt.Sprintf("unknown time zone %q", input.Timezone)
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+	if input.From != "" {
+		if opts.From, err = time.Parse(time.RFC3339, input.From); err != nil {
+			response.ErrorMessage = "from must be an RFC 3339 time, e.g. 2024-03-01T09:00:00Z"
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
+		}
+	}
+
+	jobs := make([]cronutil.StaggerJob, len(input.Jobs))
+	for i, job := range input.Jobs {
+		if diags := cron_internal.DiagnoseDialect(job.CronExpression, dialect); len(diags) > 0 {
+			response.ErrorMessage = fmt.Sprintf("Invalid cron expression for job %q: %s", job.Name, job.CronExpression)
+			response.Job = job.Name
+			response.Diagnostics = diags
+			createJsonResponse(w, response, http.StatusBadRequest)
+			return
 		}
+		jobs[i] = cronutil.StaggerJob{Job: cronutil.Job{Name: job.Name, Expression: job.CronExpression}}
+		for _, d := range []struct {
+			name, value string
+			duration    *time.Duration
+		}{{"duration", job.Duration, &jobs[i].Duration}, {"tolerance", job.Tolerance, &jobs[i].Tolerance}} {
+			if d.value == "" {
+				continue
+			}
+			if *d.duration, err = time.ParseDuration(d.value); err != nil {
+				response.ErrorMessage = fmt.Sprintf("invalid %s %q for job %q", d.name, d.value, job.Name)
+				createJsonResponse(w, response, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	if response.Plan, err = cronutil.Stagger(jobs, opts); err != nil {
+		response.ErrorMessage = err.Error()
+		createJsonResponse(w, response, http.StatusBadRequest)
+		return
 	}
 	createJsonResponse(w, response, http.StatusOK)
 }
@@ -0,0 +1,328 @@
+package cron_internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is a single parsed element of a cron field.
+type Node interface {
+	String() string
+	// expand adds every statically known value matched by the node to set.
+	// Calendar dependent nodes (L in day of month, LW, dL, W, #) add nothing.
+	expand(spec fieldSpec, set *ValueSet)
+}
+
+// Wildcard is "*", matching every value of the field.
+type Wildcard struct{}
+
+// NoSpecific is "?", accepted in the day fields to mean "no specific value".
+type NoSpecific struct{}
+
+// Value is a single value, written either as a number or a month/weekday name.
+type Value struct {
+	Value int
+	// Name holds the name as written, e.g. "MON", when the value was not numeric.
+	Name string
+}
+
+// Range is an inclusive range of values, e.g. 9-17 or MON-FRI.
+type Range struct {
+	Start, End Value
+}
+
+// Step is a base stepped by an interval, e.g. */15, 10-50/10 or 0/15. The
+// base is a Wildcard, a Range, or a Value standing for the range from that
+// value to the field's maximum.
+type Step struct {
+	Base     Node
+	Interval int
+}
+
+// List is a comma separated list of items, e.g. 1,15,30.
+type List struct {
+	Items []Node
+}
+
+// Last is "L": the last day of the month, or Saturday in the day of week
+// field. In day of month, "L-n" is written with an Offset of n days before
+// the last day.
+type Last struct {
+	Offset int
+}
+
+// LastWeekday is "LW": the last weekday (Monday to Friday) of the month.
+type LastWeekday struct{}
+
+// LastOccurrence is "dL": the last occurrence of weekday d within the month.
+type LastOccurrence struct {
+	Weekday int
+	// Name holds the weekday name as written, e.g. "FRI", when it was not
+	// numeric.
+	Name string
+}
+
+// Hash is Jenkins' "H": a value derived from a hash of a seed such as the
+// job name, so that jobs sharing a schedule spread out instead of firing
+// together. "H(a-b)" limits the value to a range and "H/n" steps by n from
+// a hashed start. See Expression.Resolve.
+type Hash struct {
+	// Range is the Range written in parentheses, or nil for the field's
+	// whole range.
+	Range    Node
+	Interval int
+}
+
+// NearestWeekday is "nW": the weekday closest to day n of the month.
+type NearestWeekday struct {
+	Day int
+}
+
+// NthWeekday is "d#n": the nth occurrence of weekday d within the month.
+type NthWeekday struct {
+	Weekday, N int
+	// Name holds the weekday name as written, e.g. "TUE", when it was not
+	// numeric.
+	Name string
+}
+
+func (Wildcard) String() string    { return "*" }
+func (NoSpecific) String() string  { return "?" }
+func (LastWeekday) String() string { return "LW" }
+
+func (l Last) String() string {
+	if l.Offset != 0 {
+		return "L-" + strconv.Itoa(l.Offset)
+	}
+	return "L"
+}
+
+func (v Value) String() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return strconv.Itoa(v.Value)
+}
+
+func (r Range) String() string { return r.Start.String() + "-" + r.End.String() }
+
+func (s Step) String() string { return s.Base.String() + "/" + strconv.Itoa(s.Interval) }
+
+func (l List) String() string {
+	items := make([]string, len(l.Items))
+	for i, item := range l.Items {
+		items[i] = item.String()
+	}
+	return strings.Join(items, ",")
+}
+
+func (w NearestWeekday) String() string { return strconv.Itoa(w.Day) + "W" }
+
+func (n NthWeekday) String() string {
+	return Value{n.Weekday, n.Name}.String() + "#" + strconv.Itoa(n.N)
+}
+
+func (l LastOccurrence) String() string { return Value{l.Weekday, l.Name}.String() + "L" }
+
+func (h Hash) String() string {
+	s := "H"
+	if h.Range != nil {
+		s += "(" + h.Range.String() + ")"
+	}
+	if h.Interval != 0 {
+		s += "/" + strconv.Itoa(h.Interval)
+	}
+	return s
+}
+
+func (Wildcard) expand(spec fieldSpec, set *ValueSet) {
+	set.AddRange(spec.min, spec.max, 1)
+}
+
+func (NoSpecific) expand(spec fieldSpec, set *ValueSet) {
+	set.AddRange(spec.min, spec.max, 1)
+}
+
+func (Last) expand(spec fieldSpec, set *ValueSet) {
+	if spec.name == fieldDayOfWeek {
+		set.Add(spec.saturday())
+	}
+}
+
+func (NearestWeekday) expand(fieldSpec, *ValueSet) {}
+func (NthWeekday) expand(fieldSpec, *ValueSet)     {}
+func (LastWeekday) expand(fieldSpec, *ValueSet)    {}
+func (LastOccurrence) expand(fieldSpec, *ValueSet) {}
+
+// expand adds the values H resolves to with an empty seed.
+func (h Hash) expand(spec fieldSpec, set *ValueSet) { h.resolve(spec, "").expand(spec, set) }
+
+func (v Value) expand(_ fieldSpec, set *ValueSet) { set.Add(v.Value) }
+
+func (r Range) expand(_ fieldSpec, set *ValueSet) { set.AddRange(r.Start.Value, r.End.Value, 1) }
+
+func (s Step) expand(spec fieldSpec, set *ValueSet) {
+	if s.Interval < 1 {
+		return
+	}
+	switch base := s.Base.(type) {
+	case Wildcard:
+		set.AddRange(spec.min, spec.max, s.Interval)
+	case Range:
+		set.AddRange(base.Start.Value, base.End.Value, s.Interval)
+	case Value:
+		end := spec.max
+		if spec.name == fieldDayOfWeek {
+			// Stop at Saturday: 7 is Sunday again, not the day after Saturday.
+			end = spec.saturday()
+		}
+		set.AddRange(base.Value, end, s.Interval)
+	}
+}
+
+func (l List) expand(spec fieldSpec, set *ValueSet) {
+	for _, item := range l.Items {
+		item.expand(spec, set)
+	}
+}
+
+// Field is a single parsed field of a cron expression.
+type Field struct {
+	// Raw is the field exactly as written in the expression.
+	Raw  string
+	Root Node
+	spec fieldSpec
+}
+
+// Name returns the human readable name of the field, e.g. "day of month".
+func (f Field) Name() string { return f.spec.name }
+
+// IsSet reports whether the field is present in the expression. Optional
+// fields such as seconds and year are unset when not written.
+func (f Field) IsSet() bool { return f.Root != nil }
+
+func (f Field) String() string {
+	if f.Root == nil {
+		return f.Raw
+	}
+	return f.Root.String()
+}
+
+// Values resolves the field to the set of values it allows. Day of week
+// values are folded so that Sunday is always 0, whatever the dialect's
+// weekday numbering. Calendar dependent items
+// are not included; see IsCalendarDependent.
+func (f Field) Values() ValueSet {
+	set := newValueSet(f.spec.min, f.spec.max)
+	if f.Root != nil {
+		f.Root.expand(f.spec, &set)
+	}
+	if f.spec.name == fieldDayOfWeek {
+		folded := newValueSet(0, 6)
+		for _, v := range set.Values() {
+			folded.Add(f.spec.weekday(v))
+		}
+		return folded
+	}
+	return set
+}
+
+// IsWildcard reports whether the field is unrestricted, i.e. "*" or "?".
+func (f Field) IsWildcard() bool {
+	switch f.Root.(type) {
+	case Wildcard, NoSpecific:
+		return true
+	}
+	return false
+}
+
+// IsCalendarDependent reports whether the field contains items whose values
+// depend on the month being evaluated (L in day of month, LW, dL, W or #).
+func (f Field) IsCalendarDependent() bool {
+	return walk(f.Root, func(n Node) bool {
+		switch n.(type) {
+		case Last:
+			return f.spec.name == fieldDayOfMonth
+		case NearestWeekday, NthWeekday, LastWeekday, LastOccurrence:
+			return true
+		}
+		return false
+	})
+}
+
+// walk reports whether fn returns true for n or any node beneath it.
+func walk(n Node, fn func(Node) bool) bool {
+	if n == nil {
+		return false
+	}
+	if fn(n) {
+		return true
+	}
+	switch n := n.(type) {
+	case List:
+		for _, item := range n.Items {
+			if walk(item, fn) {
+				return true
+			}
+		}
+	case Step:
+		return walk(n.Base, fn)
+	}
+	return false
+}
+
+// ValueSet is a set of integer values within [Min, Max].
+type ValueSet struct {
+	Min, Max int
+	present  []bool
+}
+
+func newValueSet(min, max int) ValueSet {
+	return ValueSet{Min: min, Max: max, present: make([]bool, max-min+1)}
+}
+
+// Add adds v to the set, ignoring values outside its bounds.
+func (s *ValueSet) Add(v int) {
+	if v >= s.Min && v <= s.Max {
+		s.present[v-s.Min] = true
+	}
+}
+
+// AddRange adds every step-th value from start to end inclusive.
+func (s *ValueSet) AddRange(start, end, step int) {
+	for v := start; v <= end; v += step {
+		s.Add(v)
+	}
+}
+
+// Contains reports whether v is in the set.
+func (s ValueSet) Contains(v int) bool {
+	return v >= s.Min && v <= s.Max && s.present[v-s.Min]
+}
+
+// Values returns the members of the set in ascending order.
+func (s ValueSet) Values() []int {
+	var values []int
+	for i, ok := range s.present {
+		if ok {
+			values = append(values, s.Min+i)
+		}
+	}
+	return values
+}
+
+// Len returns the number of values in the set.
+func (s ValueSet) Len() int {
+	n := 0
+	for _, ok := range s.present {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// IsFull reports whether every value between Min and Max is in the set.
+func (s ValueSet) IsFull() bool {
+	return s.Len() == len(s.present)
+}
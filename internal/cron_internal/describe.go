@@ -0,0 +1,261 @@
+package cron_internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Describe returns a plain English description of the expression, e.g.
+// "At 14:30, Monday through Friday". It is rule based and deterministic.
+func (c *Expression) Describe() string {
+	return c.DescribeIn(DefaultLocale)
+}
+
+// DescribeIn describes the expression in the given locale, falling back to
+// English for locales without a catalog. See MatchLocale.
+func (c *Expression) DescribeIn(locale string) string {
+	cat := catalogFor(locale)
+	switch {
+	case c.IsReboot():
+		return cat.startup
+	case c.IsInterval():
+		return capitalize(fmt.Sprintf(cat.interval, strings.TrimPrefix(c.Macro, macroEvery+" ")))
+	}
+
+	parts := []string{c.describeTime(cat)}
+	if days := c.describeDays(cat); days != "" {
+		parts = append(parts, days)
+	}
+	if !c.Month.IsWildcard() {
+		parts = append(parts, describeField(cat, c.Month, monthUnit))
+	}
+	if c.Year.IsSet() && !c.Year.IsWildcard() {
+		parts = append(parts, describeField(cat, c.Year, yearUnit))
+	}
+	return capitalize(strings.Join(parts, cat.partSep))
+}
+
+func (c *Expression) describeTime(cat *catalog) string {
+	second := 0
+	if c.Second.IsSet() {
+		v, ok := c.Second.Root.(Value)
+		if !ok {
+			second = -1
+		} else {
+			second = v.Value
+		}
+	}
+
+	minute, minuteOk := c.Minute.Root.(Value)
+	if minuteOk && second >= 0 {
+		if hours := plainValues(c.Hour.Root); hours != nil {
+			times := make([]string, len(hours))
+			for i, hour := range hours {
+				times[i] = formatClock(hour, minute.Value, second)
+			}
+			return fmt.Sprintf(cat.atClock, cat.join(times))
+		}
+	}
+
+	var phrases []string
+	if second != 0 {
+		phrases = append(phrases, describeField(cat, c.Second, secondUnit))
+	}
+	if !(c.Minute.IsWildcard() && len(phrases) > 0) {
+		phrases = append(phrases, describeField(cat, c.Minute, minuteUnit))
+	}
+	if !c.Hour.IsWildcard() {
+		phrases = append(phrases, describeField(cat, c.Hour, hourUnit))
+	}
+	if cat.reversePast {
+		for i, j := 0, len(phrases)-1; i < j; i, j = i+1, j-1 {
+			phrases[i], phrases[j] = phrases[j], phrases[i]
+		}
+	}
+	return fmt.Sprintf(cat.atPhrase, strings.Join(phrases, cat.past))
+}
+
+// describeDays describes the day of month and day of week fields. When both
+// are restricted a day matches if either does, so they are joined with "or".
+func (c *Expression) describeDays(cat *catalog) string {
+	var phrases []string
+	if !c.DayOfMonth.IsWildcard() {
+		phrases = append(phrases, describeField(cat, c.DayOfMonth, dayOfMonthUnit))
+	}
+	if !c.DayOfWeek.IsWildcard() {
+		phrases = append(phrases, describeField(cat, c.DayOfWeek, dayOfWeekUnit))
+	}
+	return strings.Join(phrases, cat.or)
+}
+
+// unit identifies the field being described and how its values are named.
+// For day of week, min is the number of Sunday and max that of Saturday.
+type unit struct {
+	field    string
+	min, max int
+	named    bool
+}
+
+var (
+	secondUnit     = unit{fieldSecond, 0, 59, false}
+	minuteUnit     = unit{fieldMinute, 0, 59, false}
+	hourUnit       = unit{fieldHour, 0, 23, false}
+	dayOfMonthUnit = unit{fieldDayOfMonth, 1, 31, false}
+	dayOfWeekUnit  = unit{fieldDayOfWeek, 0, 6, true}
+	monthUnit      = unit{fieldMonth, 1, 12, true}
+	yearUnit       = unit{fieldYear, yearSpec.min, yearSpec.max, false}
+)
+
+// describeField describes a field item by item, gathering plain values into
+// a single list, e.g. "minute 0, 15, and 30".
+func describeField(cat *catalog, f Field, u unit) string {
+	if u.field == fieldDayOfWeek {
+		u.min, u.max = f.spec.min, f.spec.saturday()
+	}
+	words := cat.units[u.field]
+	items := []Node{f.Root}
+	if list, ok := f.Root.(List); ok {
+		items = list.Items
+	}
+
+	var phrases, values []string
+	valuesAt := -1
+	for _, item := range items {
+		if v, ok := item.(Value); ok {
+			if valuesAt < 0 {
+				valuesAt = len(phrases)
+				phrases = append(phrases, "")
+			}
+			values = append(values, cat.name(u, v.Value))
+			continue
+		}
+		phrases = append(phrases, describeItem(cat, item, u))
+	}
+	if valuesAt >= 0 {
+		phrases[valuesAt] = cat.values(words, values...)
+	}
+
+	phrase := cat.join(phrases)
+	if !standsAlone(f.Root, u) {
+		phrase = fmt.Sprintf(words.prefix, phrase)
+	}
+	return fmt.Sprintf(words.suffix, phrase)
+}
+
+func describeItem(cat *catalog, n Node, u unit) string {
+	words := cat.units[u.field]
+	switch n := n.(type) {
+	case Wildcard, NoSpecific:
+		return words.any
+	case Value:
+		return cat.values(words, cat.name(u, n.Value))
+	case Range:
+		start, end := cat.name(u, n.Start.Value), cat.name(u, n.End.Value)
+		if cat.unitPerValue {
+			return fmt.Sprintf(cat.through, cat.values(words, start), cat.values(words, end))
+		}
+		return cat.values(words, fmt.Sprintf(cat.through, start, end))
+	case Step:
+		every := fmt.Sprintf(words.every, cat.ordinal(n.Interval))
+		switch base := n.Base.(type) {
+		case Range:
+			return fmt.Sprintf(cat.fromThrough, every, cat.name(u, base.Start.Value), cat.name(u, base.End.Value))
+		case Value:
+			return fmt.Sprintf(cat.fromThrough, every, cat.name(u, base.Value), cat.name(u, u.max))
+		}
+		return every
+	case Last:
+		if u.field == fieldDayOfWeek {
+			return cat.weekdays[6]
+		}
+		if n.Offset != 0 {
+			return cat.beforeLastDay(n.Offset)
+		}
+		return cat.lastDay
+	case LastWeekday:
+		return cat.lastWeekday
+	case NearestWeekday:
+		return fmt.Sprintf(cat.nearestWeekday, n.Day)
+	case NthWeekday:
+		return cat.nthWeekday(n.N, (n.Weekday-u.min)%7)
+	case LastOccurrence:
+		return cat.lastOccurrence((n.Weekday - u.min) % 7)
+	case Hash:
+		phrase := words.hashed
+		if n.Interval != 0 {
+			phrase = fmt.Sprintf(words.every, cat.ordinal(n.Interval))
+		}
+		if r, ok := n.Range.(Range); ok {
+			phrase = fmt.Sprintf(cat.fromThrough, phrase, cat.name(u, r.Start.Value), cat.name(u, r.End.Value))
+		}
+		if n.Interval != 0 {
+			phrase = fmt.Sprintf(cat.hashedStart, phrase)
+		}
+		return phrase
+	}
+	return n.String()
+}
+
+// standsAlone reports whether a field's phrase reads on its own without the
+// unit's prefix: "every 2nd day", "Monday through Friday" and "on the second
+// Monday of the month" rather than "on every 2nd day" or "on Monday through
+// Friday". H phrases carry their own preposition.
+func standsAlone(n Node, u unit) bool {
+	switch n.(type) {
+	case Wildcard, NoSpecific, Step, NthWeekday, LastOccurrence, Hash:
+		return true
+	case Range:
+		return u.named
+	}
+	return false
+}
+
+// plainValues returns the values of a field made only of plain values, or
+// nil if it contains anything else.
+func plainValues(n Node) []int {
+	switch n := n.(type) {
+	case Value:
+		return []int{n.Value}
+	case List:
+		values := make([]int, 0, len(n.Items))
+		for _, item := range n.Items {
+			v, ok := item.(Value)
+			if !ok {
+				return nil
+			}
+			values = append(values, v.Value)
+		}
+		return values
+	}
+	return nil
+}
+
+func formatClock(hour, minute, second int) string {
+	if second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// englishOrdinal returns n as an English ordinal number, e.g. 2nd or 11th.
+func englishOrdinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
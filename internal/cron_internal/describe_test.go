@@ -0,0 +1,132 @@
+package cron_internal
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		expression string
+		mode       ParseMode
+		want       string
+	}{
+		{"* * * * *", ModeStandard, "At every minute"},
+		{"30 14 * * 1-5", ModeStandard, "At 14:30, Monday through Friday"},
+		{"0 */2 L * *", ModeStandard, "At minute 0 past every 2nd hour, on the last day of the month"},
+		{"*/15 * * * *", ModeStandard, "At every 15th minute"},
+		{"* 9 * * *", ModeStandard, "At every minute past hour 9"},
+		{"0 9,17 * * *", ModeStandard, "At 09:00 and 17:00"},
+		{"0,30 9-17 * * *", ModeStandard, "At minute 0 and 30 past hour 9 through 17"},
+		{"5-55/10 * * * *", ModeStandard, "At every 10th minute from 5 through 55"},
+		{"0 0 1,15 * *", ModeStandard, "At 00:00, on day 1 and 15 of the month"},
+		{"0 0 13 * FRI", ModeStandard, "At 00:00, on day 13 of the month or on Friday"},
+		{"0 0 * * MON,WED,FRI", ModeStandard, "At 00:00, on Monday, Wednesday, and Friday"},
+		{"0 12 15W * *", ModeStandard, "At 12:00, on the weekday nearest day 15 of the month"},
+		{"0 12 * * 1#2", ModeStandard, "At 12:00, on the second Monday of the month"},
+		{"0 18 LW * *", ModeStandard, "At 18:00, on the last weekday of the month"},
+		{"0 0 L-2 * *", ModeStandard, "At 00:00, on the 3rd to last day of the month"},
+		{"0 0 * * 5L", ModeStandard, "At 00:00, on the last Friday of the month"},
+		{"0 0 */2 * *", ModeStandard, "At 00:00, every 2nd day of the month"},
+		{"0 0 1 JAN,JUL *", ModeStandard, "At 00:00, on day 1 of the month, in January and July"},
+		{"0 0 1 */3 *", ModeStandard, "At 00:00, on day 1 of the month, every 3rd month"},
+		{"0 0 1 JAN-MAR *", ModeStandard, "At 00:00, on day 1 of the month, January through March"},
+		{"@daily", ModeStandard, "At 00:00"},
+		{"@every 1h30m", ModeStandard, "Every 1h30m"},
+		{"@reboot", ModeStandard, "At startup"},
+		{"H H * * *", ModeStandard, "At a hashed minute past a hashed hour"},
+		{"H H(0-2) * * *", ModeStandard, "At a hashed minute past a hashed hour from 0 through 2"},
+		{"H/15 * * * *", ModeStandard, "At every 15th minute, starting at a hashed offset"},
+		{"H H H * *", ModeStandard, "At a hashed minute past a hashed hour, on a hashed day of the month"},
+		{"H H * * H", ModeStandard, "At a hashed minute past a hashed hour, on a hashed day of the week"},
+		{"30 0 9 ? * MON-FRI", ModeQuartz, "At 09:00:30, Monday through Friday"},
+		{"*/10 * * * * ?", ModeQuartz, "At every 10th second"},
+		{"0 0 12 1 1 ? 2030", ModeQuartz, "At 12:00, on day 1 of the month, in January, in 2030"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := ParseCronWithMode(tt.expression, tt.mode)
+			if err != nil {
+				t.Fatalf("ParseCronWithMode() error = %v", err)
+			}
+			if got := expr.Describe(); got != tt.want {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeIn(t *testing.T) {
+	tests := []struct {
+		locale     string
+		expression string
+		want       string
+	}{
+		{"de", "30 14 * * 1-5", "Um 14:30, Montag bis Freitag"},
+		{"de", "0 */2 L * *", "Minute 0 in jeder 2. Stunde, am letzten Tag des Monats"},
+		{"de", "5-55/10 * * * *", "Jede 10. Minute von 5 bis 55"},
+		{"de", "0 12 * * 1#2", "Um 12:00, am zweiten Montag des Monats"},
+		{"de", "0 0 1 MAR,DEC *", "Um 00:00, am Tag 1 des Monats, im März und Dezember"},
+		{"de", "0 0 * * SUN,WED,SAT", "Um 00:00, am Sonntag, Mittwoch und Samstag"},
+		{"de", "0 0 * * 5L", "Um 00:00, am letzten Freitag des Monats"},
+		{"de", "0 0 L-1 * *", "Um 00:00, am 2. letzten Tag des Monats"},
+		{"de", "H H * * *", "Eine per Hash gewählte Minute in einer per Hash gewählten Stunde"},
+		{"de", "H/15 * * * *", "Jede 15. Minute ab einem per Hash gewählten Versatz"},
+		{"ja", "30 14 * * 1-5", "14:30、月曜日から金曜日まで"},
+		{"ja", "0 */2 L * *", "2時間ごとの0分、毎月末日"},
+		{"ja", "0,30 9-17 * * *", "9時から17時までの0分と30分"},
+		{"ja", "0 12 * * 6#3", "12:00、第3土曜日"},
+		{"ja", "0 0 1 JAN,JUL *", "00:00、毎月1日、1月と7月"},
+		{"ja", "0 0 L-3 * *", "00:00、毎月末日の3日前"},
+		{"ja", "0 0 LW * *", "00:00、毎月最終平日"},
+		{"ja", "H H H * *", "ハッシュで選んだ時のハッシュで選んだ分、毎月ハッシュで選んだ日"},
+		{"pt-BR", "30 14 * * 1-5", "Às 14:30, segunda-feira a sexta-feira"},
+		{"pt-BR", "0 12 * * 1#2", "Às 12:00, na segunda segunda-feira do mês"},
+		{"pt-BR", "0 12 * * 6#3", "Às 12:00, no terceiro sábado do mês"},
+		{"pt-BR", "0 0 1 */3 *", "Às 00:00, no dia 1 do mês, a cada 3 meses"},
+		{"pt-BR", "0 0 1 FEB,SEP *", "Às 00:00, no dia 1 do mês, em fevereiro e setembro"},
+		{"pt-BR", "0 0 LW * *", "Às 00:00, no último dia útil do mês"},
+		{"pt-BR", "0 0 * * 6L", "Às 00:00, no último sábado do mês"},
+		{"pt-BR", "0 0 * * 5L", "Às 00:00, na última sexta-feira do mês"},
+		{"pt-BR", "H H * * *", "Num minuto escolhido por hash numa hora escolhida por hash"},
+		{"en", "0 0 * * 2#1", "At 00:00, on the first Tuesday of the month"},
+		{"en", "*/11 */22 * * *", "At every 11th minute past every 22nd hour"},
+		{"en", "*/21 */3 * * *", "At every 21st minute past every 3rd hour"},
+		{"fr", "30 14 * * 1-5", "At 14:30, Monday through Friday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.expression, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := expr.DescribeIn(tt.locale); got != tt.want {
+				t.Errorf("DescribeIn() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"ja-JP", "ja"},
+		{"pt-BR", "pt-BR"},
+		{"pt", "pt-BR"},
+		{"fr-FR,en;q=0.5", "en"},
+		{"fr-FR,ja;q=0.8,de;q=0.9", "de"},
+		{"de;q=0", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			if got := MatchLocale(tt.acceptLanguage); got != tt.want {
+				t.Errorf("MatchLocale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
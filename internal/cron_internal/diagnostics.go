@@ -0,0 +1,205 @@
+package cron_internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity grades how serious a Diagnostic is.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic is a single problem found in an expression, located by its
+// byte offset and length within the original string.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Field    string   `json:"field"`
+	Message  string   `json:"message"`
+	Offset   int      `json:"offset"`
+	Length   int      `json:"length"`
+	// Suggestion is the expression rewritten to fix the problem, if there
+	// is an obvious fix.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// token is a whitespace separated part of an expression and its offset.
+type token struct {
+	text   string
+	offset int
+}
+
+// Diagnose reports every problem in an expression in a single pass, unlike
+// Validate which stops at the first one. It returns nil for a valid
+// expression.
+func Diagnose(expression string, mode ParseMode) []Diagnostic {
+	return DiagnoseDialect(expression, DialectForMode(mode))
+}
+
+// DiagnoseDialect is Diagnose for an expression written for the given
+// dialect, also reporting syntax the dialect does not support.
+func DiagnoseDialect(expression string, d *Dialect) []Diagnostic {
+	var diags []Diagnostic
+	tokens := tokenize(expression)
+	if len(tokens) > 0 && isTimeZonePrefix(tokens[0].text) {
+		if _, err := parseTimeZone(tokens[0].text); err != nil {
+			diags = append(diags, newDiagnostic(err, tokens[0].offset, len(tokens[0].text)))
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && strings.HasPrefix(tokens[0].text, "@") {
+		return append(diags, diagnoseMacro(expression, tokens, d)...)
+	}
+
+	specs, countErr := d.fieldSpecs(len(tokens))
+	fields := make(map[string]Field)
+	dayOfWeekAt := -1
+
+	for i, tok := range tokens {
+		if i >= len(specs) {
+			diags = append(diags, newDiagnostic(countErr, tok.offset, len(tok.text)))
+			continue
+		}
+		if specs[i].name == fieldDayOfWeek {
+			dayOfWeekAt = i
+		}
+		f, fieldDiags := diagnoseField(d, specs[i], tok)
+		diags = append(diags, fieldDiags...)
+		if len(fieldDiags) == 0 {
+			fields[specs[i].name] = f
+		}
+	}
+
+	for i := len(tokens); i < len(specs); i++ {
+		d := newDiagnostic(countErr, len(strings.TrimRight(expression, " \t")), 1)
+		d.Field = specs[i].name
+		d.Message = "missing " + specs[i].name + " field"
+		diags = append(diags, d)
+	}
+
+	dom, domOk := fields[fieldDayOfMonth]
+	dow, dowOk := fields[fieldDayOfWeek]
+	if d.RequireNoSpecific && domOk && dowOk {
+		if err := validateDayFields(dom, dow); err != nil {
+			tok := tokens[dayOfWeekAt]
+			diags = append(diags, newDiagnostic(err, tok.offset, len(tok.text)))
+		}
+	}
+	return diags
+}
+
+func diagnoseMacro(expression string, tokens []token, d *Dialect) []Diagnostic {
+	expr, err := ParseCronDialect(expression[tokens[0].offset:], d)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+	last := tokens[len(tokens)-1]
+	start := tokens[0].offset
+	return []Diagnostic{newDiagnostic(err, start, last.offset+len(last.text)-start)}
+}
+
+// diagnoseField parses and validates each comma separated item of a field
+// on its own, so that every bad item is reported.
+func diagnoseField(d *Dialect, spec fieldSpec, tok token) (Field, []Diagnostic) {
+	var diags []Diagnostic
+	offset := tok.offset
+	for _, item := range strings.Split(tok.text, ",") {
+		node, err := parseItem(spec, item)
+		if err == nil {
+			err = validateNode(spec, node)
+		}
+		if err == nil {
+			err = d.checkNode(spec, node)
+		}
+		if err != nil {
+			length := len(item)
+			if length == 0 {
+				length = 1
+			}
+			diags = append(diags, newDiagnostic(err, offset, length))
+		}
+		offset += len(item) + 1
+	}
+	if len(diags) > 0 {
+		return Field{}, diags
+	}
+	f, _ := parseField(spec, tok.text)
+	return f, nil
+}
+
+func newDiagnostic(err error, offset, length int) Diagnostic {
+	d := Diagnostic{Severity: SeverityError, Field: "expression", Message: err.Error(), Offset: offset, Length: length}
+	var cronErr *ValidationError
+	if errors.As(err, &cronErr) {
+		d.Code, d.Field, d.Message = cronErr.Code, cronErr.Field, cronErr.Message
+	}
+	return d
+}
+
+func tokenize(expression string) []token {
+	var tokens []token
+	start := -1
+	for i := 0; i <= len(expression); i++ {
+		if i == len(expression) || expression[i] == ' ' || expression[i] == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, token{expression[start:i], start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// RenderDiagnostics prints the expression with carets under each diagnosed
+// span, followed by one line per diagnostic:
+//
+//	0 60 * * 8
+//	  ^^     ^
+//	col 3: error[out-of-range] hour: value must be between 0 and 23
+//	col 10: error[out-of-range] day of week: value must be between 0 and 7
+func RenderDiagnostics(expression string, diags []Diagnostic) string {
+	line := strings.ReplaceAll(expression, "\t", " ")
+	carets := []byte(strings.Repeat(" ", len(line)))
+	for _, d := range diags {
+		for len(carets) < d.Offset+d.Length {
+			carets = append(carets, ' ')
+		}
+		for i := d.Offset; i < d.Offset+d.Length; i++ {
+			carets[i] = '^'
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(line + "\n")
+	b.WriteString(strings.TrimRight(string(carets), " ") + "\n")
+	for _, d := range diags {
+		fmt.Fprintf(&b, "col %d: %s[%s] %s: %s\n", d.Offset+1, d.Severity, d.Code, d.Field, d.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package cron_internal
+
+import "testing"
+
+func TestDiagnose(t *testing.T) {
+	type want struct {
+		code           string
+		field          string
+		offset, length int
+	}
+	tests := []struct {
+		name       string
+		expression string
+		mode       ParseMode
+		want       []want
+	}{
+		{"Valid", "0 9 * * MON-FRI", ModeStandard, nil},
+		{"Every bad field", "0 60 * * 8", ModeStandard, []want{{CodeOutOfRange, "hour", 2, 2}, {CodeOutOfRange, "day of week", 9, 1}}},
+		{"Bad list items", "1,61,2,99 * * * *", ModeStandard, []want{{CodeOutOfRange, "minute", 2, 2}, {CodeOutOfRange, "minute", 7, 2}}},
+		{"Syntax and range errors", "*/a 5 15-10 FOO *", ModeStandard, []want{{CodeInvalidStep, "minute", 0, 3}, {CodeInvalidRange, "day of month", 6, 5}, {CodeInvalidValue, "month", 12, 3}}},
+		{"Extra field", "0 0 * * * *", ModeStandard, []want{{CodeFieldCount, "expression", 10, 1}}},
+		{"Missing field", "0 0 * *", ModeStandard, []want{{CodeFieldCount, "day of week", 7, 1}}},
+		{"Quartz day conflict", "0 0 12 * * *", ModeQuartz, []want{{CodeDayFieldConflict, "day of week", 11, 1}}},
+		{"Bad macro", "@every soon", ModeStandard, []want{{CodeInvalidMacro, "expression", 0, 11}}},
+		{"Time zone", "CRON_TZ=Asia/Kolkata 0 9 * * *", ModeStandard, nil},
+		{"Unknown time zone", "CRON_TZ=Nowhere 0 60 * * *", ModeStandard, []want{{CodeInvalidTimeZone, "expression", 0, 15}, {CodeOutOfRange, "hour", 18, 2}}},
+		{"Bad macro after a time zone", "TZ=UTC @every soon", ModeStandard, []want{{CodeInvalidMacro, "expression", 7, 11}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := Diagnose(tt.expression, tt.mode)
+			if len(diags) != len(tt.want) {
+				t.Fatalf("Diagnose() = %+v, want %d diagnostics", diags, len(tt.want))
+			}
+			for i, d := range diags {
+				got := want{d.Code, d.Field, d.Offset, d.Length}
+				if got != tt.want[i] || d.Severity != SeverityError {
+					t.Errorf("Diagnose()[%d] = %+v, want %+v", i, d, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDiagnostics(t *testing.T) {
+	expression := "0 60 * * 8"
+	want := "0 60 * * 8\n" +
+		"  ^^     ^\n" +
+		"col 3: error[out-of-range] hour: value must be between 0 and 23\n" +
+		"col 10: error[out-of-range] day of week: value must be between 0 and 7\n"
+	if got := RenderDiagnostics(expression, Diagnose(expression, ModeStandard)); got != want {
+		t.Errorf("RenderDiagnostics() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,292 @@
+package cron_internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dialect describes the cron syntax accepted by a particular scheduler: its
+// field layout, how it numbers weekdays and which special characters and
+// macros it supports.
+type Dialect struct {
+	// Name identifies the dialect in requests, e.g. "quartz".
+	Name string
+	// Title is the scheduler's display name, e.g. "AWS EventBridge".
+	Title string
+	Mode  ParseMode
+	// SundayIsOne numbers weekdays 1-7 starting from Sunday, as Quartz and
+	// EventBridge do, instead of 0-6 starting from Sunday.
+	SundayIsOne bool
+	// SundayIsSeven also accepts 7 for Sunday in 0 based numbering.
+	SundayIsSeven bool
+	// AllowLast, AllowNearestWeekday and AllowNthWeekday enable the L, W
+	// and # characters.
+	AllowLast           bool
+	AllowNearestWeekday bool
+	AllowNthWeekday     bool
+	// AllowHash enables Jenkins' H and HashedMacros expands the calendar
+	// macros with H, e.g. @daily to "H H * * *", as Jenkins does.
+	AllowHash    bool
+	HashedMacros bool
+	// AllowNoSpecific enables '?' in the day fields.
+	AllowNoSpecific bool
+	// RequireNoSpecific requires '?' in exactly one of the day fields.
+	RequireNoSpecific bool
+	// Macros lists the accepted macros, e.g. "@daily" or "@every".
+	Macros []string
+	// Prompt tells the LLM how to write expressions for the dialect. It is
+	// empty for Standard, which the base prompt already describes.
+	Prompt string
+}
+
+var (
+	calendarMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+	allMacros      = append([]string{macroReboot, macroEvery}, calendarMacros...)
+)
+
+var (
+	// Standard is the default 5 field dialect. It accepts everything the
+	// parser understands: Vixie cron plus L, W, #, H, '?' and every macro.
+	Standard = &Dialect{
+		Name:                "standard",
+		Title:               "Standard cron",
+		Mode:                ModeStandard,
+		SundayIsSeven:       true,
+		AllowLast:           true,
+		AllowNearestWeekday: true,
+		AllowNthWeekday:     true,
+		AllowHash:           true,
+		AllowNoSpecific:     true,
+		Macros:              allMacros,
+	}
+
+	// Vixie is classic Vixie cron and its descendants such as cronie.
+	Vixie = &Dialect{
+		Name:          "vixie",
+		Title:         "Vixie cron",
+		Mode:          ModeStandard,
+		SundayIsSeven: true,
+		Macros:        append([]string{macroReboot}, calendarMacros...),
+		Prompt: `Write the expression for Vixie cron: 5 fields, day of week 0-7 or SUN-SAT (0 and 7 are Sunday).
+Do not use L, W, # or ?. The macros @reboot, @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are allowed; @every is not.`,
+	}
+
+	// Quartz is the Quartz scheduler, with seconds, an optional year and
+	// weekdays numbered from 1 for Sunday.
+	Quartz = &Dialect{
+		Name:                "quartz",
+		Title:               "Quartz",
+		Mode:                ModeQuartz,
+		SundayIsOne:         true,
+		AllowLast:           true,
+		AllowNearestWeekday: true,
+		AllowNthWeekday:     true,
+		AllowNoSpecific:     true,
+		RequireNoSpecific:   true,
+		Prompt: `Write the expression for the Quartz scheduler: 6 or 7 fields, second minute hour day-of-month month day-of-week [year].
+Day of week is 1-7 or SUN-SAT where 1 is Sunday and 7 is Saturday. Exactly one of day of month and day of week must be ?.
+L, W and # are allowed. Macros are not. Example: "0 30 14 ? * MON-FRI".`,
+	}
+
+	// EventBridge is AWS EventBridge (and CloudWatch Events) cron(...)
+	// schedules, without the surrounding "cron(" and ")".
+	EventBridge = &Dialect{
+		Name:                "eventbridge",
+		Title:               "AWS EventBridge",
+		Mode:                ModeEventBridge,
+		SundayIsOne:         true,
+		AllowLast:           true,
+		AllowNearestWeekday: true,
+		AllowNthWeekday:     true,
+		AllowNoSpecific:     true,
+		RequireNoSpecific:   true,
+		Prompt: `Write the expression for AWS EventBridge: 6 fields, minute hour day-of-month month day-of-week year, without "cron(" and ")".
+Day of week is 1-7 or SUN-SAT where 1 is Sunday. Exactly one of day of month and day of week must be ?.
+L, W and # are allowed. Macros are not. Example: "30 14 ? * MON-FRI *".`,
+	}
+
+	// Kubernetes is the schedule of a Kubernetes CronJob.
+	Kubernetes = &Dialect{
+		Name:            "kubernetes",
+		Title:           "Kubernetes CronJob",
+		Mode:            ModeStandard,
+		AllowNoSpecific: true,
+		Macros:          calendarMacros,
+		Prompt: `Write the expression for a Kubernetes CronJob: 5 fields, day of week 0-6 or SUN-SAT (0 is Sunday, 7 is not allowed).
+Do not use L, W or #. The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are allowed; @reboot and @every are not.`,
+	}
+
+	// GitHubActions is the on.schedule cron syntax of GitHub Actions
+	// workflows.
+	GitHubActions = &Dialect{
+		Name:  "github-actions",
+		Title: "GitHub Actions",
+		Mode:  ModeStandard,
+		Prompt: `Write the expression for a GitHub Actions schedule: 5 fields, day of week 0-6 or SUN-SAT (0 is Sunday, 7 is not allowed).
+Do not use L, W, # or ?, and do not use macros. GitHub runs schedules at most every 5 minutes.`,
+	}
+
+	// Jenkins is the build trigger syntax of Jenkins, which adds H to
+	// spread jobs out and expands the macros with H.
+	Jenkins = &Dialect{
+		Name:          "jenkins",
+		Title:         "Jenkins",
+		Mode:          ModeStandard,
+		SundayIsSeven: true,
+		AllowHash:     true,
+		HashedMacros:  true,
+		Macros:        calendarMacros,
+		Prompt: `Write the expression for a Jenkins trigger: 5 fields, day of week 0-7 or SUN-SAT (0 and 7 are Sunday).
+Prefer H over a fixed value wherever the exact time does not matter, so that jobs spread out: H picks a stable value in the field's range,
+H(0-7) picks one in a range and H/15 steps by 15 from a picked start. Example: "H H(0-7) * * 1-5" for once early every weekday morning.
+Do not use L, W, # or ?. The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are allowed and use H; @reboot and @every are not.`,
+	}
+)
+
+// dialects lists the named dialects accepted by LookupDialect.
+var dialects = []*Dialect{Standard, Vixie, Quartz, EventBridge, Kubernetes, GitHubActions, Jenkins}
+
+// permissiveQuartz and permissiveEventBridge accept every syntax of their
+// layout, for callers that select a layout by ParseMode alone. Like
+// Quartz and EventBridge themselves, they number weekdays from 1 for
+// Sunday to 7.
+var (
+	permissiveQuartz = &Dialect{
+		Name:                "quartz-layout",
+		Title:               "Quartz layout",
+		Mode:                ModeQuartz,
+		SundayIsOne:         true,
+		AllowLast:           true,
+		AllowNearestWeekday: true,
+		AllowNthWeekday:     true,
+		AllowHash:           true,
+		AllowNoSpecific:     true,
+		RequireNoSpecific:   true,
+		Macros:              allMacros,
+	}
+	permissiveEventBridge = &Dialect{
+		Name:                "eventbridge-layout",
+		Title:               "EventBridge layout",
+		Mode:                ModeEventBridge,
+		SundayIsOne:         true,
+		AllowLast:           true,
+		AllowNearestWeekday: true,
+		AllowNthWeekday:     true,
+		AllowHash:           true,
+		AllowNoSpecific:     true,
+		RequireNoSpecific:   true,
+		Macros:              allMacros,
+	}
+)
+
+// DialectForMode returns the dialect that accepts every syntax of the
+// layout selected by mode.
+func DialectForMode(mode ParseMode) *Dialect {
+	switch mode {
+	case ModeQuartz:
+		return permissiveQuartz
+	case ModeEventBridge:
+		return permissiveEventBridge
+	}
+	return Standard
+}
+
+// LookupDialect returns the named dialect, ignoring case. An empty name
+// selects Standard.
+func LookupDialect(name string) (*Dialect, error) {
+	if name == "" {
+		return Standard, nil
+	}
+	for _, d := range dialects {
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cron dialect %q, expected one of %s", name, strings.Join(DialectNames(), ", "))
+}
+
+// DialectNames returns the names accepted by LookupDialect, sorted.
+func DialectNames() []string {
+	names := make([]string, len(dialects))
+	for i, d := range dialects {
+		names[i] = d.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *Dialect) String() string { return d.Name }
+
+func (d *Dialect) dayOfWeekSpec() fieldSpec {
+	spec := dayOfWeekSpec
+	switch {
+	case d.SundayIsOne:
+		spec.min, spec.max = 1, 7
+	case !d.SundayIsSeven:
+		spec.max = 6
+	}
+	return spec
+}
+
+func (d *Dialect) validateMacro(macro string) error {
+	if macro == "" {
+		return nil
+	}
+	name := strings.Fields(macro)[0]
+	for _, m := range d.Macros {
+		if m == name {
+			return nil
+		}
+	}
+	return &ValidationError{"expression", name + " is not supported by " + d.Title, CodeUnsupported}
+}
+
+// validate checks the parts of an expression that depend on the dialect
+// rather than on the field ranges.
+func (d *Dialect) validate(c *Expression) error {
+	for _, f := range c.Fields() {
+		if err := d.checkNode(f.spec, f.Root); err != nil {
+			return err
+		}
+	}
+	if d.RequireNoSpecific {
+		return validateDayFields(c.DayOfMonth, c.DayOfWeek)
+	}
+	return nil
+}
+
+// checkNode reports the first special character in n that the dialect does
+// not support.
+func (d *Dialect) checkNode(spec fieldSpec, n Node) error {
+	var unsupported string
+	walk(n, func(n Node) bool {
+		switch n := n.(type) {
+		case NoSpecific:
+			if !d.AllowNoSpecific {
+				unsupported = "'?'"
+			}
+		case Last, LastWeekday, LastOccurrence:
+			if !d.AllowLast {
+				unsupported = "'" + n.String() + "'"
+			}
+		case NearestWeekday:
+			if !d.AllowNearestWeekday {
+				unsupported = "'" + n.String() + "'"
+			}
+		case NthWeekday:
+			if !d.AllowNthWeekday {
+				unsupported = "'" + n.String() + "'"
+			}
+		case Hash:
+			if !d.AllowHash {
+				unsupported = "'" + n.String() + "'"
+			}
+		}
+		return unsupported != ""
+	})
+	if unsupported != "" {
+		return &ValidationError{spec.name, unsupported + " is not supported by " + d.Title, CodeUnsupported}
+	}
+	return nil
+}
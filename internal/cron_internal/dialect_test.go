@@ -0,0 +1,123 @@
+package cron_internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateCronDialect(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		dialect    *Dialect
+		wantCode   string
+	}{
+		{"Standard accepts L", "0 0 L * *", Standard, ""},
+		{"Vixie rejects L", "0 0 L * *", Vixie, CodeUnsupported},
+		{"Vixie accepts Sunday as 7", "0 0 * * 7", Vixie, ""},
+		{"Vixie rejects @every", "@every 1h", Vixie, CodeUnsupported},
+		{"Vixie accepts @reboot", "@reboot", Vixie, ""},
+		{"Kubernetes rejects Sunday as 7", "0 0 * * 7", Kubernetes, CodeOutOfRange},
+		{"Kubernetes accepts ?", "0 0 ? * 1", Kubernetes, ""},
+		{"Kubernetes rejects @reboot", "@reboot", Kubernetes, CodeUnsupported},
+		{"GitHub Actions rejects #", "0 0 * * 1#2", GitHubActions, CodeUnsupported},
+		{"GitHub Actions rejects macros", "@daily", GitHubActions, CodeUnsupported},
+		{"Quartz weekdays start at 1", "0 0 12 ? * 0", Quartz, CodeOutOfRange},
+		{"Quartz accepts Saturday as 7", "0 0 12 ? * 7", Quartz, ""},
+		{"Quartz requires ?", "0 0 12 * * 2", Quartz, CodeDayFieldConflict},
+		{"Quartz rejects macros", "@hourly", Quartz, CodeUnsupported},
+		{"EventBridge layout", "0 12 ? * MON-FRI *", EventBridge, ""},
+		{"EventBridge requires year", "0 12 ? * MON-FRI", EventBridge, CodeFieldCount},
+		{"EventBridge has no seconds", "0 0 12 ? * MON-FRI *", EventBridge, CodeFieldCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCronDialect(tt.expression, tt.dialect)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ValidateCronDialect() error = %v", err)
+				}
+				return
+			}
+			var cronErr *ValidationError
+			if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+				t.Fatalf("ValidateCronDialect() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDialectWeekdayNumbering(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		dialect    *Dialect
+		from       string
+		want       string
+		normalized string
+		described  string
+	}{
+		{"Quartz Monday is 2", "0 0 9 ? * 2", Quartz, "2024-03-08T00:00:00Z", "2024-03-11T09:00:00Z", "0 0 9 ? * 2", "At 09:00, on Monday"},
+		{"Quartz names", "0 0 9 ? * MON-FRI", Quartz, "2024-03-09T00:00:00Z", "2024-03-11T09:00:00Z", "0 0 9 ? * 2-6", "At 09:00, Monday through Friday"},
+		{"Quartz Saturday is 7", "0 0 9 ? * 7", Quartz, "2024-03-08T00:00:00Z", "2024-03-09T09:00:00Z", "0 0 9 ? * 7", "At 09:00, on Saturday"},
+		{"Quartz nth weekday", "0 0 9 ? * 6#3", Quartz, "2024-03-01T00:00:00Z", "2024-03-15T09:00:00Z", "0 0 9 ? * 6#3", "At 09:00, on the third Friday of the month"},
+		{"Quartz last weekday is Saturday", "0 0 9 ? * L", Quartz, "2024-03-08T00:00:00Z", "2024-03-09T09:00:00Z", "0 0 9 ? * 7", "At 09:00, on Saturday"},
+		{"Quartz last Friday", "0 0 9 ? * 6L", Quartz, "2024-03-01T00:00:00Z", "2024-03-29T09:00:00Z", "0 0 9 ? * 6L", "At 09:00, on the last Friday of the month"},
+		{"EventBridge", "30 14 ? * 1 *", EventBridge, "2024-03-08T00:00:00Z", "2024-03-10T14:30:00Z", "30 14 ? * 1 *", "At 14:30, on Sunday"},
+		{"Kubernetes", "0 9 * * 1", Kubernetes, "2024-03-08T00:00:00Z", "2024-03-11T09:00:00Z", "0 9 * * 1", "At 09:00, on Monday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronDialect(tt.expression, tt.dialect)
+			if err == nil {
+				err = expr.Validate()
+			}
+			if err != nil {
+				t.Fatalf("ParseCronDialect() error = %v", err)
+			}
+			from, _ := time.Parse(time.RFC3339, tt.from)
+			if got := expr.Next(from).Format(time.RFC3339); got != tt.want {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+			if got := expr.Normalize(NormalizeOptions{}).String(); got != tt.normalized {
+				t.Errorf("Normalize() = %q, want %q", got, tt.normalized)
+			}
+			if got := expr.Describe(); got != tt.described {
+				t.Errorf("Describe() = %q, want %q", got, tt.described)
+			}
+		})
+	}
+}
+
+func TestEquivalentAcrossDialects(t *testing.T) {
+	quartz, _ := ParseCronDialect("0 0 9 ? * 2", Quartz)
+	standard, _ := ParseCronDialect("0 9 * * 1", Standard)
+	if ok, counterexample := Equivalent(quartz, standard); !ok {
+		t.Errorf("Equivalent() = false at %s, want true", counterexample)
+	}
+
+	layout, _ := ParseCronWithMode("0 0 9 ? * 2", ModeQuartz)
+	if ok, counterexample := Equivalent(quartz, layout); !ok {
+		t.Errorf("Equivalent() = false at %s, want ModeQuartz to number weekdays like Quartz", counterexample)
+	}
+
+	tuesday, _ := ParseCronWithMode("0 0 9 ? * 3", ModeQuartz)
+	if ok, _ := Equivalent(quartz, tuesday); ok {
+		t.Errorf("Equivalent() = true for Monday and Tuesday")
+	}
+}
+
+func TestLookupDialect(t *testing.T) {
+	if d, err := LookupDialect(""); err != nil || d != Standard {
+		t.Errorf("LookupDialect(\"\") = %v, %v, want standard", d, err)
+	}
+	if d, err := LookupDialect("Quartz"); err != nil || d != Quartz {
+		t.Errorf("LookupDialect(\"Quartz\") = %v, %v, want quartz", d, err)
+	}
+	if _, err := LookupDialect("fcron"); err == nil {
+		t.Errorf("LookupDialect(\"fcron\") error = nil, want error")
+	}
+}
@@ -0,0 +1,174 @@
+package cron_internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxDSTOccurrences bounds the report for schedules that fire every minute
+// or second through a transition.
+const maxDSTOccurrences = 100
+
+// DSTEffect is how a daylight saving transition changes an occurrence.
+type DSTEffect string
+
+const (
+	// DSTSkipped occurrences fall in the hour the clocks skip, so they do
+	// not run that day.
+	DSTSkipped DSTEffect = "skipped"
+	// DSTDuplicated occurrences fall in the hour the clocks repeat, so they
+	// run twice.
+	DSTDuplicated DSTEffect = "duplicated"
+	// DSTShifted occurrences of an @every schedule keep their interval in
+	// elapsed time, so from the transition on they run at a different time
+	// on the wall clock.
+	DSTShifted DSTEffect = "shifted"
+)
+
+// DSTOccurrence is an occurrence affected by a daylight saving transition.
+type DSTOccurrence struct {
+	Effect DSTEffect `json:"effect"`
+	// Transition is the instant the clocks change.
+	Transition time.Time `json:"transition"`
+	// WallClock is the local time the occurrence is scheduled for, without
+	// an offset since a skipped time has none and a duplicated one has two.
+	WallClock string `json:"wall_clock"`
+	// Times are the instants the occurrence runs at: none when skipped, two
+	// when duplicated and one when shifted.
+	Times   []time.Time `json:"times,omitempty"`
+	Message string      `json:"message"`
+}
+
+// DSTReport returns the occurrences affected by daylight saving transitions
+// within horizon of from, in the expression's Location or, without one, in
+// from's location, as Next would run them: a calendar schedule skips the
+// times the clocks jump over and runs twice at the times they repeat, and
+// an @every schedule drifts on the wall clock. At most 100 occurrences are
+// reported.
+func (c *Expression) DSTReport(from time.Time, horizon time.Duration) []DSTOccurrence {
+	if c.IsReboot() {
+		return nil
+	}
+	loc := from.Location()
+	if c.Location != nil {
+		loc = c.Location
+	}
+	end := from.Add(horizon)
+
+	var report []DSTOccurrence
+	for _, transition := range transitions(from.In(loc), end) {
+		if c.IsInterval() {
+			report = append(report, c.intervalShift(from, transition, end)...)
+		} else {
+			report = append(report, c.wallClockEffects(transition)...)
+		}
+		if len(report) >= maxDSTOccurrences {
+			return report[:maxDSTOccurrences]
+		}
+	}
+	return report
+}
+
+// transitions returns the instants in (t, end] at which t's location
+// changes its UTC offset.
+func transitions(t, end time.Time) []time.Time {
+	var instants []time.Time
+	for {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || next.After(end) || !next.After(t) {
+			return instants
+		}
+		if _, before := next.Add(-time.Second).Zone(); before != offset(next) {
+			instants = append(instants, next)
+		}
+		t = next
+	}
+}
+
+// wallClockEffects returns the occurrences of a calendar schedule whose
+// wall clock time the transition skips or repeats.
+func (c *Expression) wallClockEffects(transition time.Time) []DSTOccurrence {
+	_, before := transition.Add(-time.Second).Zone()
+	after := offset(transition)
+	change := describeChange(transition, before)
+
+	// Wall clock times are matched by running the schedule in UTC, which
+	// has no transitions, on times whose fields are the local ones.
+	wall := *c
+	wall.Location = nil
+	var start, stop time.Time
+	if after > before {
+		start = wallClock(transition.In(time.FixedZone("", before)))
+		stop = start.Add(time.Duration(after-before) * time.Second)
+	} else {
+		start = wallClock(transition)
+		stop = start.Add(time.Duration(before-after) * time.Second)
+	}
+
+	var report []DSTOccurrence
+	for w := wall.Next(start.Add(-time.Second)); !w.IsZero() && w.Before(stop); w = wall.Next(w) {
+		occurrence := DSTOccurrence{Transition: transition, WallClock: w.Format("2006-01-02T15:04:05")}
+		if after > before {
+			occurrence.Effect = DSTSkipped
+			occurrence.Message = fmt.Sprintf("%s does not exist on %s because %s, so this run is skipped", w.Format("15:04:05"), w.Format("2006-01-02"), change)
+		} else {
+			occurrence.Effect = DSTDuplicated
+			occurrence.Times = []time.Time{
+				time.Unix(w.Unix()-int64(before), 0).In(transition.Location()),
+				time.Unix(w.Unix()-int64(after), 0).In(transition.Location()),
+			}
+			occurrence.Message = fmt.Sprintf("%s happens twice on %s because %s, so this runs twice", w.Format("15:04:05"), w.Format("2006-01-02"), change)
+		}
+		report = append(report, occurrence)
+	}
+	return report
+}
+
+// intervalShift returns the first run of an @every schedule started at from
+// that falls at or after the transition, if it is not after end.
+func (c *Expression) intervalShift(from, transition, end time.Time) []DSTOccurrence {
+	start := from.Truncate(time.Second)
+	n := (transition.Sub(start) + c.Interval - 1) / c.Interval
+	if n < 1 {
+		n = 1
+	}
+	run := start.Add(n * c.Interval).In(transition.Location())
+	if run.After(end) {
+		return nil
+	}
+	_, before := transition.Add(-time.Second).Zone()
+	shift := time.Duration(offset(transition)-before) * time.Second
+	direction := "later"
+	if shift < 0 {
+		shift, direction = -shift, "earlier"
+	}
+	return []DSTOccurrence{{
+		Effect:     DSTShifted,
+		Transition: transition,
+		WallClock:  run.Format("2006-01-02T15:04:05"),
+		Times:      []time.Time{run},
+		Message: fmt.Sprintf("@every %s counts elapsed time, so after %s this and later runs happen %s %s on the wall clock",
+			c.Interval, describeChange(transition, before), shift, direction),
+	}}
+}
+
+// describeChange says how the clocks change at the transition, e.g.
+// "clocks go forward from 02:00 to 03:00".
+func describeChange(transition time.Time, before int) string {
+	from := transition.In(time.FixedZone("", before)).Format("15:04")
+	to := transition.Format("15:04")
+	if offset(transition) > before {
+		return fmt.Sprintf("clocks go forward from %s to %s", from, to)
+	}
+	return fmt.Sprintf("clocks go back from %s to %s", from, to)
+}
+
+func offset(t time.Time) int {
+	_, offset := t.Zone()
+	return offset
+}
+
+// wallClock returns a UTC time with the same fields as t's local time.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
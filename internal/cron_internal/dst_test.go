@@ -0,0 +1,92 @@
+package cron_internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDSTReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []string
+	}{
+		{"Skipped in spring", "30 2 * * *", []string{"skipped 2024-03-10T02:30:00"}},
+		{"Duplicated in autumn", "30 1 * * *", []string{"duplicated 2024-11-03T01:30:00 2024-11-03T05:30:00Z 2024-11-03T06:30:00Z"}},
+		{"Unaffected", "0 12 * * *", nil},
+		{"Every half hour overnight", "*/30 1-2 * * *", []string{
+			"skipped 2024-03-10T02:00:00",
+			"skipped 2024-03-10T02:30:00",
+			"duplicated 2024-11-03T01:00:00 2024-11-03T05:00:00Z 2024-11-03T06:00:00Z",
+			"duplicated 2024-11-03T01:30:00 2024-11-03T05:30:00Z 2024-11-03T06:30:00Z",
+		}},
+		{"Other days", "30 2 * * MON", nil},
+		{"Interval", "@every 24h", []string{
+			"shifted 2024-03-10T10:00:00 2024-03-10T14:00:00Z",
+			"shifted 2024-11-03T09:00:00 2024-11-03T14:00:00Z",
+		}},
+		{"Reboot", "@reboot", nil},
+		{"Zone prefix", "CRON_TZ=UTC 30 2 * * *", nil},
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2024, 1, 1, 9, 0, 0, 0, ny)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			var got []string
+			for _, o := range expr.DSTReport(from, 365*24*time.Hour) {
+				parts := []string{string(o.Effect), o.WallClock}
+				for _, at := range o.Times {
+					parts = append(parts, at.UTC().Format(time.RFC3339))
+				}
+				got = append(got, strings.Join(parts, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("DSTReport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDSTReportMatchesNext(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	expr, _ := ParseCron("*/30 1-2 * * *")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, ny)
+
+	runs := make(map[time.Time]bool)
+	for next := expr.Next(from); next.Year() == 2024; next = expr.Next(next) {
+		runs[next] = true
+	}
+	for _, o := range expr.DSTReport(from, 366*24*time.Hour) {
+		for _, at := range o.Times {
+			if !runs[at] {
+				t.Errorf("%s reported at %s, but Next does not run then", o.Effect, at)
+			}
+		}
+		if o.Effect == DSTSkipped {
+			for at := range runs {
+				if at.Format("2006-01-02T15:04:05") == o.WallClock {
+					t.Errorf("%s reported as skipped, but Next runs at %s", o.WallClock, at)
+				}
+			}
+		}
+	}
+}
+
+func TestDSTReportHorizon(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	expr, _ := ParseCron("30 1 * * *")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, ny)
+	if got := expr.DSTReport(from, 30*24*time.Hour); len(got) != 0 {
+		t.Errorf("DSTReport() within 30 days = %v, want none", got)
+	}
+	everySecond, _ := ParseCronDialect("* * * * * ?", Quartz)
+	if got := everySecond.DSTReport(from, 365*24*time.Hour); len(got) != maxDSTOccurrences {
+		t.Errorf("DSTReport() for every second has %d occurrences, want %d", len(got), maxDSTOccurrences)
+	}
+}
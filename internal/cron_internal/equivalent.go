@@ -0,0 +1,108 @@
+package cron_internal
+
+import "time"
+
+// equivalenceYears is the span searched for a difference between two
+// expressions. The Gregorian calendar repeats every 400 years, and starting
+// at the minimum year also covers every value of a year field.
+const equivalenceYears = 400
+
+// Equivalent reports whether a and b fire at exactly the same times. When
+// they do not, it also returns the earliest time, in UTC from 1970 onwards,
+// at which exactly one of them fires. Day of month and day of week are
+// combined with cron's OR rule when both are restricted. Time zone
+// prefixes are ignored: expressions are compared by wall clock time.
+//
+// @every and @reboot expressions are only equivalent to identical ones, and
+// never come with a counterexample.
+func Equivalent(a, b *Expression) (bool, time.Time) {
+	if a.IsInterval() || a.IsReboot() || b.IsInterval() || b.IsReboot() {
+		return a.IsReboot() == b.IsReboot() && a.Interval == b.Interval, time.Time{}
+	}
+	// Normal forms are only comparable when both dialects number weekdays
+	// alike.
+	opts := NormalizeOptions{}
+	sameWeekdays := a.DayOfWeek.spec.min == b.DayOfWeek.spec.min
+	if sameWeekdays && withSeconds(a).Normalize(opts).String() == withSeconds(b).Normalize(opts).String() {
+		return true, time.Time{}
+	}
+
+	timesA, timesB := timesOfDay(a), timesOfDay(b)
+	timesEqual := equalValues(timesA, timesB)
+
+	for year := yearSpec.min; year < yearSpec.min+equivalenceYears; year++ {
+		for month := time.January; month <= time.December; month++ {
+			daysA, daysB := a.daysFiring(year, month), b.daysFiring(year, month)
+			for day := 1; day <= daysInMonth(year, month); day++ {
+				onA, onB := daysA.Contains(day), daysB.Contains(day)
+				if (!onA && !onB) || (onA && onB && timesEqual) {
+					continue
+				}
+				for _, tod := range mergeValues(timesA, timesB) {
+					if (onA && timesA.Contains(tod)) != (onB && timesB.Contains(tod)) {
+						return false, time.Date(year, month, day, tod/3600, tod/60%60, tod%60, 0, time.UTC)
+					}
+				}
+			}
+		}
+	}
+	return true, time.Time{}
+}
+
+// withSeconds returns the expression with an explicit seconds field, so
+// that 5 field and Quartz expressions normalize alike.
+func withSeconds(c *Expression) *Expression {
+	if c.Second.IsSet() {
+		return c
+	}
+	n := *c
+	n.Second, _ = parseField(secondSpec, "0")
+	return &n
+}
+
+// daysFiring returns the days of a month on which the expression fires,
+// taking the month and year fields into account.
+func (c *Expression) daysFiring(year int, month time.Month) ValueSet {
+	if !c.Month.Values().Contains(int(month)) || (c.Year.IsSet() && !c.Year.Values().Contains(year)) {
+		return newValueSet(1, daysInMonth(year, month))
+	}
+	return c.DaysIn(year, month)
+}
+
+// timesOfDay returns the seconds after midnight at which the expression
+// fires on a day it is scheduled.
+func timesOfDay(c *Expression) ValueSet {
+	seconds, minutes, hours := c.secondValues(), c.Minute.Values(), c.Hour.Values()
+	set := newValueSet(0, 24*60*60-1)
+	for _, h := range hours.Values() {
+		for _, m := range minutes.Values() {
+			for _, s := range seconds.Values() {
+				set.Add(h*3600 + m*60 + s)
+			}
+		}
+	}
+	return set
+}
+
+func equalValues(a, b ValueSet) bool {
+	if a.Min != b.Min || a.Max != b.Max {
+		return false
+	}
+	for v := a.Min; v <= a.Max; v++ {
+		if a.Contains(v) != b.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeValues returns the union of two sets with the same bounds, in order.
+func mergeValues(a, b ValueSet) []int {
+	var values []int
+	for v := a.Min; v <= a.Max; v++ {
+		if a.Contains(v) || b.Contains(v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
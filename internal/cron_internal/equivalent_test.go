@@ -0,0 +1,59 @@
+package cron_internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		modeB  ParseMode
+		want   bool
+		wantAt string
+	}{
+		{"0 9 * * MON-FRI", "0 9 * * 1,2,3,4,5", ModeStandard, true, ""},
+		{"0 0 * * 0", "0 0 * * 7", ModeStandard, true, ""},
+		{"*/20 * * * *", "0,20,40 * * * *", ModeStandard, true, ""},
+		{"@daily", "0 0 * * *", ModeStandard, true, ""},
+		{"0 0 1-31 * 1", "0 0 * * *", ModeStandard, true, ""},
+		{"0 12 * * 1-5", "0 0 12 ? * MON-FRI", ModeQuartz, true, ""},
+		{"0 0 L * *", "0 0 28-31 * *", ModeStandard, false, "1970-01-28T00:00:00Z"},
+		{"0 9 * * 1-5", "0 9 * * 1-6", ModeStandard, false, "1970-01-03T09:00:00Z"},
+		{"0 9 * * *", "30 9 * * *", ModeStandard, false, "1970-01-01T09:00:00Z"},
+		{"0 0 13 * 5", "0 0 13 * *", ModeStandard, false, "1970-01-02T00:00:00Z"},
+		{"0 0 29 2 *", "0 0 29 2 *", ModeStandard, true, ""},
+		{"0 0 30 2 *", "0 0 31 2 *", ModeStandard, true, ""},
+		{"@every 1h", "@every 1h", ModeStandard, true, ""},
+		{"@every 1h", "0 * * * *", ModeStandard, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, err := ParseCron(tt.a)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			b, err := ParseCronWithMode(tt.b, tt.modeB)
+			if err != nil {
+				t.Fatalf("ParseCronWithMode() error = %v", err)
+			}
+			got, at := Equivalent(a, b)
+			if got != tt.want {
+				t.Fatalf("Equivalent() = %v, want %v", got, tt.want)
+			}
+			if tt.wantAt == "" {
+				if !at.IsZero() {
+					t.Errorf("Equivalent() counterexample = %v, want none", at)
+				}
+				return
+			}
+			if gotAt := at.Format(time.RFC3339); gotAt != tt.wantAt {
+				t.Errorf("Equivalent() counterexample = %s, want %s", gotAt, tt.wantAt)
+			}
+			if a.Matches(at) == b.Matches(at) {
+				t.Errorf("counterexample %v matches both or neither expression", at)
+			}
+		})
+	}
+}
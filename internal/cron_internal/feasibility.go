@@ -0,0 +1,125 @@
+package cron_internal
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// feasibilityYears is the span checked by Feasibility when the expression
+// has no year field: a full 400 year Gregorian cycle, after which the
+// calendar repeats.
+const feasibilityYears = 400
+
+// Feasibility reports schedules that are valid field by field but never or
+// rarely fire on the calendar: an error with CodeNeverFires for February
+// 30th or the 31st of a 30 day month, a warning with CodeUnreachableMonth
+// for listed months in which the day fields never match, and a warning with
+// CodeFiresRarely for schedules firing less than once a year on average,
+// such as February 29th. Offsets are left at zero; see DiagnoseFeasibility.
+func (c *Expression) Feasibility() []Diagnostic {
+	if c.IsReboot() || c.IsInterval() {
+		return nil
+	}
+
+	var years []int
+	if c.Year.IsSet() && !c.Year.IsWildcard() {
+		years = c.Year.Values().Values()
+	} else {
+		for year := 2000; year < 2000+feasibilityYears; year++ {
+			years = append(years, year)
+		}
+	}
+
+	var perMonth [13]int
+	total := 0
+	for _, year := range years {
+		for month := time.January; month <= time.December; month++ {
+			n := c.daysFiring(year, month).Len()
+			perMonth[month] += n
+			total += n
+		}
+	}
+
+	dayFields := fieldDayOfMonth
+	if !c.DayOfWeek.IsWildcard() {
+		dayFields = fieldDayOfWeek
+	}
+	switch {
+	case total == 0:
+		message := "no date matches the day and month fields, so the schedule never fires"
+		if c.Year.IsSet() && !c.Year.IsWildcard() {
+			message = "no date in the listed years matches the day and month fields, so the schedule never fires"
+		}
+		return []Diagnostic{{Severity: SeverityError, Code: CodeNeverFires, Field: dayFields, Message: message}}
+	case total < len(years):
+		gap := int(math.Round(float64(len(years)) / float64(total)))
+		message := fmt.Sprintf("fires on only %d days in %d years", total, len(years))
+		if gap > 1 {
+			message += fmt.Sprintf(", about once every %d years", gap)
+		}
+		return []Diagnostic{{Severity: SeverityWarning, Code: CodeFiresRarely, Field: dayFields, Message: message}}
+	}
+
+	if c.Month.IsWildcard() {
+		return nil
+	}
+	var unreachable []string
+	for _, month := range c.Month.Values().Values() {
+		if perMonth[month] == 0 {
+			unreachable = append(unreachable, englishCatalog.months[month-1])
+		}
+	}
+	if len(unreachable) == 0 {
+		return nil
+	}
+	return []Diagnostic{{
+		Severity: SeverityWarning,
+		Code:     CodeUnreachableMonth,
+		Field:    fieldMonth,
+		Message:  "the day fields never match in " + englishCatalog.join(unreachable) + ", so those months never fire",
+	}}
+}
+
+// DiagnoseFeasibility parses an expression written for the given dialect
+// and returns its Feasibility diagnostics, located at the fields they are
+// about. It returns nil for expressions that do not parse or validate; see
+// DiagnoseDialect for those.
+func DiagnoseFeasibility(expression string, d *Dialect) []Diagnostic {
+	expr, err := ParseCronDialect(expression, d)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err != nil {
+		return nil
+	}
+	return locateDiagnostics(expression, d, expr.Feasibility())
+}
+
+// locateDiagnostics sets the offset and length of diagnostics about a
+// whole field to that field's text in the expression, or to the whole
+// expression for macros.
+func locateDiagnostics(expression string, d *Dialect, diags []Diagnostic) []Diagnostic {
+	tokens := tokenize(expression)
+	if len(tokens) > 0 && isTimeZonePrefix(tokens[0].text) {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && strings.HasPrefix(tokens[0].text, "@") {
+		last := tokens[len(tokens)-1]
+		for i := range diags {
+			diags[i].Offset, diags[i].Length = tokens[0].offset, last.offset+len(last.text)-tokens[0].offset
+		}
+		return diags
+	}
+
+	specs, _ := d.fieldSpecs(len(tokens))
+	for i := range diags {
+		for j, spec := range specs {
+			if spec.name == diags[i].Field && j < len(tokens) {
+				diags[i].Offset, diags[i].Length = tokens[j].offset, len(tokens[j].text)
+			}
+		}
+	}
+	return diags
+}
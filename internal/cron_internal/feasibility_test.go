@@ -0,0 +1,45 @@
+package cron_internal
+
+import "testing"
+
+func TestFeasibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		mode       ParseMode
+		wantCode   string
+		wantOffset int
+	}{
+		{"Daily", "0 0 * * *", ModeStandard, "", 0},
+		{"February 30th", "0 0 30 2 *", ModeStandard, CodeNeverFires, 4},
+		{"31st of 30 day months", "0 0 31 4,6,9,11 *", ModeStandard, CodeNeverFires, 4},
+		{"February 29th", "0 0 29 2 *", ModeStandard, CodeFiresRarely, 4},
+		{"31st with a 30 day month", "0 0 31 1,4 *", ModeStandard, CodeUnreachableMonth, 7},
+		{"Last day is always reachable", "0 0 L 2,4 *", ModeStandard, "", 0},
+		{"Offset before February", "0 0 L-29 2 *", ModeStandard, CodeNeverFires, 4},
+		{"Offset reaching the 1st only in leap years", "0 0 L-28 2 *", ModeStandard, CodeFiresRarely, 4},
+		{"Weekday rescues the date", "0 0 30 2 MON", ModeStandard, "", 0},
+		{"Fifth Monday of February", "0 0 * 2 1#5", ModeStandard, CodeFiresRarely, 8},
+		{"Year without the date", "0 0 12 29 2 ? 2025", ModeQuartz, CodeNeverFires, 7},
+		{"Single year", "0 0 12 1 1 ? 2030", ModeQuartz, "", 0},
+		{"Interval", "@every 1h", ModeStandard, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := DiagnoseFeasibility(tt.expression, DialectForMode(tt.mode))
+			if tt.wantCode == "" {
+				if len(diags) > 0 {
+					t.Fatalf("DiagnoseFeasibility() = %+v, want none", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("DiagnoseFeasibility() = %+v, want one %s", diags, tt.wantCode)
+			}
+			if diags[0].Code != tt.wantCode || diags[0].Offset != tt.wantOffset {
+				t.Errorf("DiagnoseFeasibility() = %+v, want code %s at %d", diags[0], tt.wantCode, tt.wantOffset)
+			}
+		})
+	}
+}
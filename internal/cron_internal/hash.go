@@ -0,0 +1,81 @@
+package cron_internal
+
+import "hash/fnv"
+
+// bounds returns the values H chooses from: its range, or the field's whole
+// range. A bare H in day of month stays within 1-28 so that it fires every
+// month, and in day of week it stops at Saturday so that Sunday, which 7
+// also names, is not twice as likely.
+func (h Hash) bounds(spec fieldSpec) (min, max int) {
+	if r, ok := h.Range.(Range); ok {
+		return r.Start.Value, r.End.Value
+	}
+	switch spec.name {
+	case fieldDayOfMonth:
+		return spec.min, 28
+	case fieldDayOfWeek:
+		return spec.min, spec.saturday()
+	}
+	return spec.min, spec.max
+}
+
+// resolve returns the concrete node H stands for under seed: a Value, or a
+// stepped Range starting at a hashed offset, e.g. H/15 in the minute field
+// may become 7-59/15.
+func (h Hash) resolve(spec fieldSpec, seed string) Node {
+	min, max := h.bounds(spec)
+	hash := fnv.New64a()
+	hash.Write([]byte(seed + "\x00" + spec.name + "\x00" + h.String()))
+	sum := hash.Sum64()
+
+	if h.Interval == 0 {
+		return Value{Value: min + int(sum%uint64(max-min+1))}
+	}
+	start := min + int(sum%uint64(h.Interval))
+	return Step{Range{Value{Value: start}, Value{Value: max}}, h.Interval}
+}
+
+// IsHashed reports whether the expression uses Jenkins' H in any field.
+func (c *Expression) IsHashed() bool {
+	for _, f := range c.Fields() {
+		if walk(f.Root, func(n Node) bool { _, ok := n.(Hash); return ok }) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the expression with every H replaced by the values chosen
+// for seed, such as a job name. The same seed always resolves to the same
+// expression, while different seeds spread out across each H's range.
+// Hashed macros are replaced by their resolved fields. Expressions without
+// H are returned unchanged.
+//
+// The choice is stable across releases but does not reproduce the values
+// Jenkins itself would pick.
+func (c *Expression) Resolve(seed string) *Expression {
+	if !c.IsHashed() {
+		return c
+	}
+	r := *c
+	r.Macro = ""
+	for _, f := range c.Fields() {
+		root := resolveHashes(f.spec, f.Root, seed)
+		*r.field(f.spec.name) = Field{Raw: root.String(), Root: root, spec: f.spec}
+	}
+	return &r
+}
+
+func resolveHashes(spec fieldSpec, n Node, seed string) Node {
+	switch n := n.(type) {
+	case Hash:
+		return n.resolve(spec, seed)
+	case List:
+		items := make([]Node, len(n.Items))
+		for i, item := range n.Items {
+			items[i] = resolveHashes(spec, item, seed)
+		}
+		return List{Items: items}
+	}
+	return n
+}
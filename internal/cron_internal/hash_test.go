@@ -0,0 +1,98 @@
+package cron_internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		seed       string
+		want       string
+	}{
+		{"Hour in a range", "H H(0-7) * * *", "nightly-build", "37 2 * * *"},
+		{"Another seed", "H H(0-7) * * *", "release", "58 3 * * *"},
+		{"Hashed step", "H(0-29)/10 H * * H", "nightly-build", "2-29/10 11 * * 2"},
+		{"Day of month stays within 28", "0 0 H * *", "nightly-build", "0 0 1 * *"},
+		{"In a list", "H,30 9 * * *", "a", "44,30 9 * * *"},
+		{"Hashed macro", "@daily", "nightly-build", "37 11 * * *"},
+		{"Hashed @midnight", "@midnight", "release", "58 0 * * *"},
+		{"Without H", "0 9 * * 1-5", "nightly-build", "0 9 * * 1-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveCron(tt.expression, Jenkins, tt.seed)
+			if err != nil {
+				t.Fatalf("ResolveCron() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCron() = %q, want %q", got, tt.want)
+			}
+			if err := ValidateCronDialect(got, Vixie); err != nil {
+				t.Errorf("resolved expression %q is not plain cron: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestResolveSpreadsSeeds(t *testing.T) {
+	expr, _ := ParseCronDialect("H H(0-7) * * *", Jenkins)
+	from := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	seen := make(map[time.Time]bool)
+	for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		next := expr.Resolve(seed).Next(from)
+		if next.Hour() > 7 {
+			t.Errorf("Resolve(%q) fires at %s, outside H(0-7)", seed, next)
+		}
+		seen[next] = true
+	}
+	if len(seen) < 4 {
+		t.Errorf("8 seeds resolved to only %d distinct times", len(seen))
+	}
+}
+
+func TestHashValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		dialect    *Dialect
+		wantCode   string
+	}{
+		{"Jenkins accepts H", "H/15 H(9-17) * * 1-5", Jenkins, ""},
+		{"Standard accepts H", "H H * * *", Standard, ""},
+		{"Vixie rejects H", "H H * * *", Vixie, CodeUnsupported},
+		{"Jenkins rejects L", "0 0 L * *", Jenkins, CodeUnsupported},
+		{"Step larger than the range", "H(0-7)/10 * * * *", Jenkins, CodeInvalidHash},
+		{"Range out of bounds", "H(0-60) * * * *", Jenkins, CodeInvalidRange},
+		{"Unclosed range", "H(0-7 * * * *", Jenkins, CodeInvalidHash},
+		{"Trailing text", "Hx * * * *", Jenkins, CodeInvalidHash},
+		{"Jenkins rejects @reboot", "@reboot", Jenkins, CodeUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCronDialect(tt.expression, tt.dialect)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ValidateCronDialect() error = %v", err)
+				}
+				return
+			}
+			var cronErr *ValidationError
+			if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+				t.Fatalf("ValidateCronDialect() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeepsHash(t *testing.T) {
+	expr, _ := ParseCronDialect("@weekly", Jenkins)
+	if got, want := expr.Normalize(NormalizeOptions{}).String(), "H H * * H"; got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+package cron_internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintRule is a named check for expressions that are valid but almost
+// always a mistake.
+type LintRule struct {
+	// Name is reported as the Code of the rule's diagnostics.
+	Name     string
+	Severity Severity
+	// Description explains what the rule looks for.
+	Description string
+	check       func(c *Expression) []Diagnostic
+}
+
+// LintRules are the rules run by Lint, in the order they are reported.
+var LintRules = []LintRule{
+	{"wildcard-minute", SeverityWarning, "'*' in the minute field with a restricted hour runs every minute of that hour, not once", lintWildcardMinute},
+	{"wildcard-second", SeverityWarning, "'*' in the second field with a restricted minute runs every second of that minute, not once", lintWildcardSecond},
+	{"day-fields-or", SeverityWarning, "restricting both day of month and day of week fires when either matches, not when both do", lintDayFieldsOr},
+	{"duplicate-sunday", SeverityInfo, "listing both 0 and 7 names Sunday twice", lintDuplicateSunday},
+	{"uneven-step", SeverityWarning, "a step that does not divide the field's range leaves an uneven gap where the field wraps around", lintUnevenStep},
+}
+
+// Lint runs LintRules over the expression. Offsets are left at zero; see
+// LintCron.
+func (c *Expression) Lint() []Diagnostic {
+	if c.Macro != "" {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, rule := range LintRules {
+		for _, d := range rule.check(c) {
+			d.Severity, d.Code = rule.Severity, rule.Name
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// LintCron parses an expression written for the given dialect and returns
+// its Lint diagnostics, located at the fields they are about. It returns
+// nil for expressions that do not parse or validate; see DiagnoseDialect
+// for those.
+func LintCron(expression string, d *Dialect) []Diagnostic {
+	expr, err := ParseCronDialect(expression, d)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err != nil {
+		return nil
+	}
+	return locateDiagnostics(expression, d, expr.Lint())
+}
+
+// withField returns the expression with one field's text replaced.
+func (c *Expression) withField(name, value string) string {
+	fields := c.Fields()
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.String()
+		if f.spec.name == name {
+			parts[i] = value
+		}
+	}
+	return c.timeZonePrefix() + strings.Join(parts, " ")
+}
+
+func lintWildcardMinute(c *Expression) []Diagnostic {
+	if _, ok := c.Minute.Root.(Wildcard); !ok || c.Hour.IsWildcard() {
+		return nil
+	}
+	return []Diagnostic{{
+		Field:      fieldMinute,
+		Message:    fmt.Sprintf("runs every minute while the hour is %s; use 0 to run once at the start of the hour", c.Hour),
+		Suggestion: c.withField(fieldMinute, "0"),
+	}}
+}
+
+func lintWildcardSecond(c *Expression) []Diagnostic {
+	if _, ok := c.Second.Root.(Wildcard); !ok || c.Minute.IsWildcard() {
+		return nil
+	}
+	return []Diagnostic{{
+		Field:      fieldSecond,
+		Message:    fmt.Sprintf("runs every second while the minute is %s; use 0 to run once at the start of the minute", c.Minute),
+		Suggestion: c.withField(fieldSecond, "0"),
+	}}
+}
+
+func lintDayFieldsOr(c *Expression) []Diagnostic {
+	if c.DayOfMonth.IsWildcard() || c.DayOfWeek.IsWildcard() {
+		return nil
+	}
+	return []Diagnostic{{
+		Field:      fieldDayOfWeek,
+		Message:    fmt.Sprintf("fires on day %s of the month or on day of week %s, not only when both match", c.DayOfMonth, c.DayOfWeek),
+		Suggestion: c.withField(fieldDayOfMonth, wildcardLike(c.DayOfMonth).String()),
+	}}
+}
+
+func lintDuplicateSunday(c *Expression) []Diagnostic {
+	f := c.DayOfWeek
+	if f.IsWildcard() || f.spec.max != 7 || f.spec.min != 0 {
+		return nil
+	}
+	set := newValueSet(f.spec.min, f.spec.max)
+	f.Root.expand(f.spec, &set)
+	if !set.Contains(0) || !set.Contains(7) {
+		return nil
+	}
+	return []Diagnostic{{
+		Field:      fieldDayOfWeek,
+		Message:    "0 and 7 both mean Sunday",
+		Suggestion: c.withField(fieldDayOfWeek, normalizeField(f, NormalizeOptions{}).String()),
+	}}
+}
+
+func lintUnevenStep(c *Expression) []Diagnostic {
+	var diags []Diagnostic
+	for _, f := range c.Fields() {
+		step, ok := f.Root.(Step)
+		if _, wildcard := step.Base.(Wildcard); !ok || !wildcard || step.Interval <= 1 {
+			continue
+		}
+		switch f.spec.name {
+		case fieldSecond, fieldMinute, fieldHour, fieldMonth:
+			span := f.spec.max - f.spec.min + 1
+			last := f.spec.min + (span-1)/step.Interval*step.Interval
+			if gap := f.spec.min + span - last; gap != step.Interval {
+				diags = append(diags, Diagnostic{
+					Field:      f.spec.name,
+					Message:    fmt.Sprintf("%s runs every %d until %d, then again after only %d when the %s wraps around", f, step.Interval, last, gap, f.spec.name),
+					Suggestion: c.withField(f.spec.name, fmt.Sprintf("*/%d", nearestDivisor(span, step.Interval))),
+				})
+			}
+		case fieldDayOfMonth:
+			if d, ok := unevenMonthDays(c, f, step.Interval); ok {
+				diags = append(diags, d)
+			}
+		}
+	}
+	return diags
+}
+
+// unevenMonthDays reports a day of month step that restarts on the 1st so
+// soon that the schedule is not "every n days", e.g. */25 runs on the 1st
+// and 26th, then again 5 or 6 days later.
+func unevenMonthDays(c *Expression, f Field, interval int) (Diagnostic, bool) {
+	values := f.Values().Values()
+	for days := 28; days <= 31; days++ {
+		last := 1
+		for _, v := range values {
+			if v <= days {
+				last = v
+			}
+		}
+		gap := days + 1 - last
+		if 2*(interval-gap) > interval || 2*(gap-interval) > interval {
+			items := make([]string, len(values))
+			for i, v := range values {
+				items[i] = fmt.Sprint(v)
+			}
+			unit := "days"
+			if gap == 1 {
+				unit = "day"
+			}
+			// Months differ in length, so no step is even across them and
+			// there is no suggestion.
+			return Diagnostic{
+				Field:   fieldDayOfMonth,
+				Message: fmt.Sprintf("%s runs on days %s and restarts on the 1st, so some gaps are %d %s rather than %d", f, strings.Join(items, ","), gap, unit, interval),
+			}, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+// nearestDivisor returns the divisor of span closest to n, preferring the
+// smaller one on a tie.
+func nearestDivisor(span, n int) int {
+	best := 1
+	for d := 1; d <= span; d++ {
+		if span%d == 0 && abs(d-n) < abs(best-n) {
+			best = d
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package cron_internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintCron(t *testing.T) {
+	tests := []struct {
+		name           string
+		expression     string
+		mode           ParseMode
+		wantCodes      []string
+		wantSuggestion string
+	}{
+		{"Clean", "0 9 * * 1-5", ModeStandard, nil, ""},
+		{"Every minute of an hour", "* 9 * * *", ModeStandard, []string{"wildcard-minute"}, "0 9 * * *"},
+		{"Every minute of every hour", "* * * * *", ModeStandard, nil, ""},
+		{"Every second of a minute", "* 30 9 * * ?", ModeQuartz, []string{"wildcard-second"}, "0 30 9 * * ?"},
+		{"Both day fields", "0 0 1 * MON", ModeStandard, []string{"day-fields-or"}, "0 0 * * MON"},
+		{"Sunday twice", "0 0 * * 0,6,7", ModeStandard, []string{"duplicate-sunday"}, "0 0 * * 0,6"},
+		{"Sunday twice in a range", "0 0 * * 5-7,0", ModeStandard, []string{"duplicate-sunday"}, "0 0 * * 0,5,6"},
+		{"Uneven minutes", "*/7 * * * *", ModeStandard, []string{"uneven-step"}, "*/6 * * * *"},
+		{"Even minutes", "*/15 * * * *", ModeStandard, nil, ""},
+		{"Uneven months", "0 0 1 */5 *", ModeStandard, []string{"uneven-step"}, "0 0 1 */4 *"},
+		{"Uneven days of month", "0 0 */25 * *", ModeStandard, []string{"uneven-step"}, ""},
+		{"Every other day", "0 0 */2 * *", ModeStandard, nil, ""},
+		{"Several rules", "* 9 1 * 0,7", ModeStandard, []string{"wildcard-minute", "day-fields-or", "duplicate-sunday"}, "0 9 1 * 0,7"},
+		{"Macro", "@hourly", ModeStandard, nil, ""},
+		{"Time zone kept", "CRON_TZ=UTC * 9 * * *", ModeStandard, []string{"wildcard-minute"}, "CRON_TZ=UTC 0 9 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := LintCron(tt.expression, DialectForMode(tt.mode))
+			var codes []string
+			for _, d := range diags {
+				codes = append(codes, d.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Fatalf("LintCron() codes = %v, want %v", codes, tt.wantCodes)
+			}
+			if len(diags) > 0 && diags[0].Suggestion != tt.wantSuggestion {
+				t.Errorf("LintCron() suggestion = %q, want %q", diags[0].Suggestion, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
+func TestLintCronUnevenMonthDays(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{"0 0 */25 * *", "*/25 runs on days 1,26 and restarts on the 1st, so some gaps are 3 days rather than 25"},
+		{"0 0 */30 * *", "*/30 runs on days 1,31 and restarts on the 1st, so some gaps are 1 day rather than 30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			diags := LintCron(tt.expression, Standard)
+			if len(diags) != 1 || diags[0].Message != tt.want {
+				t.Fatalf("LintCron() = %+v, want message %q", diags, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintCronOffsets(t *testing.T) {
+	diags := LintCron("*  9 * * *", Standard)
+	if len(diags) != 1 || diags[0].Offset != 0 || diags[0].Length != 1 || diags[0].Severity != SeverityWarning {
+		t.Fatalf("LintCron() = %+v, want a warning at offset 0", diags)
+	}
+}
@@ -0,0 +1,350 @@
+package cron_internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when no supported locale is requested.
+const DefaultLocale = "en"
+
+// catalog holds the words and phrase templates used to describe
+// expressions in one locale.
+type catalog struct {
+	// atClock and atPhrase introduce the time of day, e.g. "At %s".
+	atClock, atPhrase string
+	startup           string
+	// interval describes an @every duration, e.g. "Every %s".
+	interval string
+	// past joins time phrases, e.g. "minute 0" + " past " + "hour 9".
+	past string
+	// reversePast puts coarser time units first, for languages that read
+	// "hour 9's minute 0".
+	reversePast bool
+	partSep     string
+	or          string
+	// listSep, pairSep and lastSep join lists as "a, b, and c" and "a and b".
+	listSep, pairSep, lastSep string
+	// unitPerValue repeats the unit word for every value, as in "0分と30分",
+	// instead of once for the list, as in "minute 0 and 30".
+	unitPerValue bool
+
+	through, fromThrough string
+	lastDay              string
+	beforeLastDay        func(n int) string
+	lastWeekday          string
+	nearestWeekday       string
+	hashedStart          string
+	nthWeekday           func(n, weekday int) string
+	lastOccurrence       func(weekday int) string
+	ordinal              func(n int) string
+	weekdays             [7]string
+	months               [12]string
+	units                map[string]unitWords
+}
+
+// unitWords holds the templates for a single field in one locale.
+type unitWords struct {
+	// any describes a wildcard, e.g. "every minute".
+	any string
+	// every describes a step given its ordinal, e.g. "every %s minute".
+	every string
+	// values introduces plain values, e.g. "minute %s".
+	values string
+	// prefix and suffix wrap the field phrase, e.g. "on %s" and "%s of the month".
+	prefix, suffix string
+	// hashed describes Jenkins' H, e.g. "a hashed minute", and stands alone
+	// without the prefix. The catalog's hashedStart describes H/n given the
+	// step's phrase, e.g. "%s, starting at a hashed offset".
+	hashed string
+}
+
+func (c *catalog) join(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + c.pairSep + items[1]
+	}
+	return strings.Join(items[:len(items)-1], c.listSep) + c.lastSep + items[len(items)-1]
+}
+
+func (c *catalog) values(words unitWords, values ...string) string {
+	if !c.unitPerValue {
+		return fmt.Sprintf(words.values, c.join(values))
+	}
+	for i, v := range values {
+		values[i] = fmt.Sprintf(words.values, v)
+	}
+	return c.join(values)
+}
+
+func (c *catalog) name(u unit, v int) string {
+	switch {
+	case u.field == fieldDayOfWeek:
+		return c.weekdays[(v-u.min)%7]
+	case u.field == fieldMonth && v >= 1 && v <= 12:
+		return c.months[v-1]
+	}
+	return strconv.Itoa(v)
+}
+
+var catalogs = map[string]*catalog{
+	"en":    englishCatalog,
+	"de":    germanCatalog,
+	"ja":    japaneseCatalog,
+	"pt-BR": portugueseCatalog,
+}
+
+// Locales returns the supported locales, sorted.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func catalogFor(locale string) *catalog {
+	if cat, ok := catalogs[MatchLocale(locale)]; ok {
+		return cat
+	}
+	return englishCatalog
+}
+
+// MatchLocale picks the best supported locale for a language tag or an
+// Accept-Language header value such as "de-DE,de;q=0.9,en;q=0.8". Tags
+// match exactly or by primary language, so "pt" and "pt-PT" both select
+// pt-BR. It returns DefaultLocale if nothing matches.
+func MatchLocale(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag != "" && q > 0 {
+			candidates = append(candidates, candidate{strings.ToLower(strings.TrimSpace(tag)), q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		for locale := range catalogs {
+			if strings.ToLower(locale) == c.tag {
+				return locale
+			}
+		}
+		primary, _, _ := strings.Cut(c.tag, "-")
+		for _, locale := range Locales() {
+			if p, _, _ := strings.Cut(strings.ToLower(locale), "-"); p == primary {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+var englishWeekdays = [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+var englishCatalog = &catalog{
+	atClock:        "At %s",
+	atPhrase:       "At %s",
+	startup:        "At startup",
+	interval:       "Every %s",
+	past:           " past ",
+	partSep:        ", ",
+	or:             " or ",
+	listSep:        ", ",
+	pairSep:        " and ",
+	lastSep:        ", and ",
+	through:        "%s through %s",
+	fromThrough:    "%s from %s through %s",
+	lastDay:        "the last day",
+	lastWeekday:    "the last weekday",
+	nearestWeekday: "the weekday nearest day %d",
+	hashedStart:    "%s, starting at a hashed offset",
+	nthWeekday: func(n, weekday int) string {
+		words := []string{"first", "second", "third", "fourth", "fifth"}
+		return fmt.Sprintf("on the %s %s of the month", ordinalIn(words, n, englishOrdinal), englishWeekdays[weekday])
+	},
+	beforeLastDay: func(n int) string {
+		return fmt.Sprintf("the %s to last day", englishOrdinal(n+1))
+	},
+	lastOccurrence: func(weekday int) string {
+		return fmt.Sprintf("on the last %s of the month", englishWeekdays[weekday])
+	},
+	ordinal:  englishOrdinal,
+	weekdays: englishWeekdays,
+	months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	units: map[string]unitWords{
+		fieldSecond:     {"every second", "every %s second", "second %s", "%s", "%s", "a hashed second"},
+		fieldMinute:     {"every minute", "every %s minute", "minute %s", "%s", "%s", "a hashed minute"},
+		fieldHour:       {"every hour", "every %s hour", "hour %s", "%s", "%s", "a hashed hour"},
+		fieldDayOfMonth: {"every day", "every %s day", "day %s", "on %s", "%s of the month", "on a hashed day"},
+		fieldDayOfWeek:  {"every day of the week", "every %s day of the week", "%s", "on %s", "%s", "on a hashed day of the week"},
+		fieldMonth:      {"every month", "every %s month", "%s", "in %s", "%s", "in a hashed month"},
+		fieldYear:       {"every year", "every %s year", "%s", "in %s", "%s", "in a hashed year"},
+	},
+}
+
+var germanWeekdays = [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}
+
+var germanCatalog = &catalog{
+	atClock:        "Um %s",
+	atPhrase:       "%s",
+	startup:        "Beim Systemstart",
+	interval:       "Alle %s",
+	past:           " in ",
+	partSep:        ", ",
+	or:             " oder ",
+	listSep:        ", ",
+	pairSep:        " und ",
+	lastSep:        " und ",
+	through:        "%s bis %s",
+	fromThrough:    "%s von %s bis %s",
+	lastDay:        "letzten Tag",
+	lastWeekday:    "letzten Werktag",
+	nearestWeekday: "nächsten Werktag zum Tag %d",
+	hashedStart:    "%s ab einem per Hash gewählten Versatz",
+	nthWeekday: func(n, weekday int) string {
+		words := []string{"ersten", "zweiten", "dritten", "vierten", "fünften"}
+		return fmt.Sprintf("am %s %s des Monats", ordinalIn(words, n, germanOrdinal), germanWeekdays[weekday])
+	},
+	beforeLastDay: func(n int) string {
+		return fmt.Sprintf("%d. letzten Tag", n+1)
+	},
+	lastOccurrence: func(weekday int) string {
+		return fmt.Sprintf("am letzten %s des Monats", germanWeekdays[weekday])
+	},
+	ordinal:  germanOrdinal,
+	weekdays: germanWeekdays,
+	months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	units: map[string]unitWords{
+		fieldSecond:     {"jede Sekunde", "jede %s Sekunde", "Sekunde %s", "%s", "%s", "eine per Hash gewählte Sekunde"},
+		fieldMinute:     {"jede Minute", "jede %s Minute", "Minute %s", "%s", "%s", "eine per Hash gewählte Minute"},
+		fieldHour:       {"jeder Stunde", "jeder %s Stunde", "Stunde %s", "%s", "%s", "einer per Hash gewählten Stunde"},
+		fieldDayOfMonth: {"jeden Tag", "jeden %s Tag", "Tag %s", "am %s", "%s des Monats", "an einem per Hash gewählten Tag"},
+		fieldDayOfWeek:  {"jeden Wochentag", "jeden %s Wochentag", "%s", "am %s", "%s", "an einem per Hash gewählten Wochentag"},
+		fieldMonth:      {"jeden Monat", "jeden %s Monat", "%s", "im %s", "%s", "in einem per Hash gewählten Monat"},
+		fieldYear:       {"jedes Jahr", "jedes %s Jahr", "%s", "im Jahr %s", "%s", "in einem per Hash gewählten Jahr"},
+	},
+}
+
+var japaneseWeekdays = [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"}
+
+var japaneseCatalog = &catalog{
+	atClock:        "%s",
+	atPhrase:       "%s",
+	startup:        "起動時",
+	interval:       "%sごと",
+	past:           "の",
+	reversePast:    true,
+	partSep:        "、",
+	or:             "または",
+	listSep:        "、",
+	pairSep:        "と",
+	lastSep:        "と",
+	unitPerValue:   true,
+	through:        "%sから%sまで",
+	fromThrough:    "%[2]sから%[3]sまで%[1]s",
+	lastDay:        "末日",
+	lastWeekday:    "最終平日",
+	nearestWeekday: "%d日に最も近い平日",
+	hashedStart:    "ハッシュで選んだ位置から%s",
+	nthWeekday: func(n, weekday int) string {
+		return fmt.Sprintf("第%d%s", n, japaneseWeekdays[weekday])
+	},
+	beforeLastDay: func(n int) string {
+		return fmt.Sprintf("末日の%d日前", n)
+	},
+	lastOccurrence: func(weekday int) string {
+		return "最終" + japaneseWeekdays[weekday]
+	},
+	ordinal:  strconv.Itoa,
+	weekdays: japaneseWeekdays,
+	months:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+	units: map[string]unitWords{
+		fieldSecond:     {"毎秒", "%s秒ごと", "%s秒", "%s", "%s", "ハッシュで選んだ秒"},
+		fieldMinute:     {"毎分", "%s分ごと", "%s分", "%s", "%s", "ハッシュで選んだ分"},
+		fieldHour:       {"毎時", "%s時間ごと", "%s時", "%s", "%s", "ハッシュで選んだ時"},
+		fieldDayOfMonth: {"毎日", "%s日ごと", "%s日", "%s", "毎月%s", "ハッシュで選んだ日"},
+		fieldDayOfWeek:  {"毎日", "%s曜日ごと", "%s", "%s", "%s", "ハッシュで選んだ曜日"},
+		fieldMonth:      {"毎月", "%sか月ごと", "%s", "%s", "%s", "ハッシュで選んだ月"},
+		fieldYear:       {"毎年", "%s年ごと", "%s年", "%s", "%s", "ハッシュで選んだ年"},
+	},
+}
+
+var portugueseWeekdays = [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}
+
+var portugueseCatalog = &catalog{
+	atClock:        "Às %s",
+	atPhrase:       "%s",
+	startup:        "Na inicialização",
+	interval:       "A cada %s",
+	past:           " ",
+	partSep:        ", ",
+	or:             " ou ",
+	listSep:        ", ",
+	pairSep:        " e ",
+	lastSep:        " e ",
+	through:        "%s a %s",
+	fromThrough:    "%s, de %s a %s",
+	lastDay:        "último dia",
+	lastWeekday:    "último dia útil",
+	nearestWeekday: "dia útil mais próximo do dia %d",
+	hashedStart:    "%s, a partir de um deslocamento escolhido por hash",
+	nthWeekday: func(n, weekday int) string {
+		// Sábado and domingo are masculine, the -feira days are feminine.
+		if weekday == 0 || weekday == 6 {
+			words := []string{"primeiro", "segundo", "terceiro", "quarto", "quinto"}
+			return fmt.Sprintf("no %s %s do mês", ordinalIn(words, n, strconv.Itoa), portugueseWeekdays[weekday])
+		}
+		words := []string{"primeira", "segunda", "terceira", "quarta", "quinta"}
+		return fmt.Sprintf("na %s %s do mês", ordinalIn(words, n, strconv.Itoa), portugueseWeekdays[weekday])
+	},
+	beforeLastDay: func(n int) string {
+		return fmt.Sprintf("%dº dia a contar do fim", n+1)
+	},
+	lastOccurrence: func(weekday int) string {
+		if weekday == 0 || weekday == 6 {
+			return "no último " + portugueseWeekdays[weekday] + " do mês"
+		}
+		return "na última " + portugueseWeekdays[weekday] + " do mês"
+	},
+	ordinal:  strconv.Itoa,
+	weekdays: portugueseWeekdays,
+	months:   [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	units: map[string]unitWords{
+		fieldSecond:     {"a cada segundo", "a cada %s segundos", "no segundo %s", "%s", "%s", "num segundo escolhido por hash"},
+		fieldMinute:     {"a cada minuto", "a cada %s minutos", "no minuto %s", "%s", "%s", "num minuto escolhido por hash"},
+		fieldHour:       {"a cada hora", "a cada %s horas", "na hora %s", "%s", "%s", "numa hora escolhida por hash"},
+		fieldDayOfMonth: {"todo dia", "a cada %s dias", "dia %s", "no %s", "%s do mês", "num dia escolhido por hash"},
+		fieldDayOfWeek:  {"todo dia da semana", "a cada %s dias da semana", "%s", "%s", "%s", "num dia da semana escolhido por hash"},
+		fieldMonth:      {"todo mês", "a cada %s meses", "%s", "em %s", "%s", "num mês escolhido por hash"},
+		fieldYear:       {"todo ano", "a cada %s anos", "%s", "em %s", "%s", "num ano escolhido por hash"},
+	},
+}
+
+// ordinalIn returns the nth word of a locale's spelled out ordinals, or
+// the numeric ordinal when n is out of range.
+func ordinalIn(words []string, n int, numeric func(int) string) string {
+	if n >= 1 && n <= len(words) {
+		return words[n-1]
+	}
+	return numeric(n)
+}
+
+func germanOrdinal(n int) string { return strconv.Itoa(n) + "." }
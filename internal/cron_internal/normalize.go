@@ -0,0 +1,158 @@
+package cron_internal
+
+import "sort"
+
+// NormalizeOptions controls the canonical form produced by Normalize.
+type NormalizeOptions struct {
+	// UseNames prints months and weekdays as names, e.g. MON-FRI, instead
+	// of numbers.
+	UseNames bool
+}
+
+// Normalize returns the canonical form of the expression, so that
+// equivalent spellings such as "0 9 * * MON-FRI" and "0 9 * * 1,2,3,4,5"
+// normalize to the same string. Names become numbers, 7 becomes 0 for
+// Sunday in dialects that number weekdays from 0, lists are sorted and
+// deduplicated, runs of three or more values become ranges, evenly
+// spaced values become steps and fields that allow every value become
+// '*'. Fields using H are kept as written. Calendar macros are expanded;
+// @every and @reboot are returned unchanged.
+func (c *Expression) Normalize(opts NormalizeOptions) *Expression {
+	if c.IsInterval() || c.IsReboot() {
+		n := *c
+		return &n
+	}
+
+	n := &Expression{Mode: c.Mode, Dialect: c.Dialect, Location: c.Location}
+	for _, f := range c.Fields() {
+		*n.field(f.spec.name) = normalizeField(f, opts)
+	}
+
+	// A restricted day field that allows every day makes every day match,
+	// whatever the other day field says.
+	if isFullRestriction(c.DayOfMonth) || isFullRestriction(c.DayOfWeek) {
+		n.DayOfMonth = wildcardLike(c.DayOfMonth)
+		n.DayOfWeek = wildcardLike(c.DayOfWeek)
+	}
+	return n
+}
+
+func isFullRestriction(f Field) bool {
+	return !f.IsWildcard() && !f.IsCalendarDependent() && f.Values().IsFull()
+}
+
+// wildcardLike returns '?' for fields written as '?', which Quartz
+// expressions must keep, and '*' otherwise.
+func wildcardLike(f Field) Field {
+	var root Node = Wildcard{}
+	if _, ok := f.Root.(NoSpecific); ok {
+		root = NoSpecific{}
+	}
+	return Field{Raw: root.String(), Root: root, spec: f.spec}
+}
+
+func normalizeField(f Field, opts NormalizeOptions) Field {
+	if f.IsWildcard() {
+		return wildcardLike(f)
+	}
+	if walk(f.Root, func(n Node) bool { _, ok := n.(Hash); return ok }) {
+		// H only has values once resolved; keep it as written.
+		return Field{Raw: f.Root.String(), Root: f.Root, spec: f.spec}
+	}
+
+	values := f.Values()
+	var items []Node
+	if values.IsFull() && !f.IsCalendarDependent() && !f.spec.isDayField() {
+		items = []Node{Wildcard{}}
+	} else {
+		items = compressValues(values, f.spec, opts)
+	}
+	items = append(items, calendarItems(f)...)
+
+	var root Node = List{Items: items}
+	if len(items) == 1 {
+		root = items[0]
+	}
+	return Field{Raw: root.String(), Root: root, spec: f.spec}
+}
+
+// compressValues writes a set of values as the shortest canonical items:
+// a single step when the values are evenly spaced, otherwise a sorted list
+// where runs of three or more become ranges.
+func compressValues(set ValueSet, spec fieldSpec, opts NormalizeOptions) []Node {
+	values := set.Values()
+	value := func(v int) Value {
+		index := v - set.Min
+		if spec.name == fieldDayOfWeek {
+			// Weekdays are folded to 0-6 by Values; number them as the
+			// dialect does.
+			v += spec.min
+		}
+		if opts.UseNames && spec.names != nil {
+			return Value{Value: v, Name: spec.names[index]}
+		}
+		return Value{Value: v}
+	}
+
+	if spec.name != fieldDayOfWeek && len(values) >= 3 {
+		step := values[1] - values[0]
+		evenlySpaced := step > 1
+		for i := 2; i < len(values) && evenlySpaced; i++ {
+			evenlySpaced = values[i]-values[i-1] == step
+		}
+		if evenlySpaced {
+			first, last := values[0], values[len(values)-1]
+			if first == set.Min && last+step > set.Max {
+				return []Node{Step{Wildcard{}, step}}
+			}
+			return []Node{Step{Range{value(first), value(last)}, step}}
+		}
+	}
+
+	var items []Node
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, Range{value(values[i]), value(values[j])})
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, value(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return items
+}
+
+// calendarItems returns the L, LW, dL, W and # items of a field in a stable order.
+func calendarItems(f Field) []Node {
+	var items []Node
+	seen := make(map[string]bool)
+	walk(f.Root, func(n Node) bool {
+		switch item := n.(type) {
+		case Last:
+			if f.spec.name == fieldDayOfWeek {
+				return false
+			}
+		case NthWeekday:
+			item.Weekday, item.Name = f.spec.weekday(item.Weekday)+f.spec.min, ""
+			n = item
+		case LastOccurrence:
+			item.Weekday, item.Name = f.spec.weekday(item.Weekday)+f.spec.min, ""
+			n = item
+		case NearestWeekday, LastWeekday:
+		default:
+			return false
+		}
+		if !seen[n.String()] {
+			seen[n.String()] = true
+			items = append(items, n)
+		}
+		return false
+	})
+	sort.SliceStable(items, func(i, j int) bool { return items[i].String() < items[j].String() })
+	return items
+}
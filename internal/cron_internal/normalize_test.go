@@ -0,0 +1,48 @@
+package cron_internal
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		expression string
+		mode       ParseMode
+		opts       NormalizeOptions
+		want       string
+	}{
+		{"0 9 * * MON-FRI", ModeStandard, NormalizeOptions{}, "0 9 * * 1-5"},
+		{"0 9 * * 1,2,3,4,5", ModeStandard, NormalizeOptions{}, "0 9 * * 1-5"},
+		{"TZ=Europe/Berlin 0 9 * * 1,2,3,4,5", ModeStandard, NormalizeOptions{}, "CRON_TZ=Europe/Berlin 0 9 * * 1-5"},
+		{"0 9 * * 5,4,3,2,1,3", ModeStandard, NormalizeOptions{}, "0 9 * * 1-5"},
+		{"0 9 * * 1,2,3,4,5", ModeStandard, NormalizeOptions{UseNames: true}, "0 9 * * MON-FRI"},
+		{"0 0 * * 7", ModeStandard, NormalizeOptions{}, "0 0 * * 0"},
+		{"0 0 * * 0,7,6", ModeStandard, NormalizeOptions{UseNames: true}, "0 0 * * SUN,SAT"},
+		{"0-59 0-23 * 1-12 *", ModeStandard, NormalizeOptions{}, "* * * * *"},
+		{"0,15,30,45 * * * *", ModeStandard, NormalizeOptions{}, "*/15 * * * *"},
+		{"*/1 * * * *", ModeStandard, NormalizeOptions{}, "* * * * *"},
+		{"5,20,35,50 * * * *", ModeStandard, NormalizeOptions{}, "5-50/15 * * * *"},
+		{"0 9,10,11,17,12 * * *", ModeStandard, NormalizeOptions{}, "0 9-12,17 * * *"},
+		{"0 0 1 JAN,APR,JUL,OCT *", ModeStandard, NormalizeOptions{}, "0 0 1 */3 *"},
+		{"0 0 1 jan,feb *", ModeStandard, NormalizeOptions{UseNames: true}, "0 0 1 JAN,FEB *"},
+		{"0 0 1-31 * MON", ModeStandard, NormalizeOptions{}, "0 0 * * *"},
+		{"0 0 * * 0-6", ModeStandard, NormalizeOptions{}, "0 0 * * *"},
+		{"0 0 1-15 * MON", ModeStandard, NormalizeOptions{}, "0 0 1-15 * 1"},
+		{"0 0 L,15W,1 * *", ModeStandard, NormalizeOptions{}, "0 0 1,15W,L * *"},
+		{"0 0 * * 7#2", ModeStandard, NormalizeOptions{}, "0 0 * * 0#2"},
+		{"@weekly", ModeStandard, NormalizeOptions{}, "0 0 * * 0"},
+		{"@every 1h", ModeStandard, NormalizeOptions{}, "@every 1h"},
+		{"0 0 12 ? * MON-FRI", ModeQuartz, NormalizeOptions{}, "0 0 12 ? * 2-6"},
+		{"0 0 12 ? * 1-7", ModeQuartz, NormalizeOptions{}, "0 0 12 ? * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := ParseCronWithMode(tt.expression, tt.mode)
+			if err != nil {
+				t.Fatalf("ParseCronWithMode() error = %v", err)
+			}
+			if got := expr.Normalize(tt.opts).String(); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,184 +4,640 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ValidationError struct {
 	Field   string
 	Message string
+	// Code is a stable identifier for the kind of problem, one of the Code
+	// constants.
+	Code string
 }
 
+// Stable problem codes reported in ValidationError and Diagnostic.
+const (
+	CodeFieldCount            = "field-count"
+	CodeInvalidValue          = "invalid-value"
+	CodeOutOfRange            = "out-of-range"
+	CodeInvalidRange          = "invalid-range"
+	CodeInvalidStep           = "invalid-step"
+	CodeInvalidList           = "invalid-list"
+	CodeInvalidNearestWeekday = "invalid-nearest-weekday"
+	CodeInvalidNthWeekday     = "invalid-nth-weekday"
+	CodeInvalidLast           = "invalid-last"
+	CodeInvalidHash           = "invalid-hash"
+	CodeInvalidTimeZone       = "invalid-time-zone"
+	CodeInvalidMacro          = "invalid-macro"
+	CodeDayFieldConflict      = "day-field-conflict"
+	CodeUnsupported           = "unsupported"
+	CodeNeverFires            = "never-fires"
+	CodeFiresRarely           = "fires-rarely"
+	CodeUnreachableMonth      = "unreachable-month"
+)
+
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("cron_internal validation error in %s: %s", e.Field, e.Message)
 }
 
+const (
+	fieldSecond     = "second"
+	fieldMinute     = "minute"
+	fieldHour       = "hour"
+	fieldDayOfMonth = "day of month"
+	fieldMonth      = "month"
+	fieldDayOfWeek  = "day of week"
+	fieldYear       = "year"
+)
+
+var (
+	monthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// fieldSpec describes the allowed values of a single cron field. Names map
+// to values starting at min, so JAN is 1 and SUN is 0, or 1 in dialects
+// that number weekdays from 1.
+type fieldSpec struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	secondSpec     = fieldSpec{fieldSecond, 0, 59, nil}
+	minuteSpec     = fieldSpec{fieldMinute, 0, 59, nil}
+	hourSpec       = fieldSpec{fieldHour, 0, 23, nil}
+	dayOfMonthSpec = fieldSpec{fieldDayOfMonth, 1, 31, nil}
+	monthSpec      = fieldSpec{fieldMonth, 1, 12, monthNames}
+	dayOfWeekSpec  = fieldSpec{fieldDayOfWeek, 0, 7, weekdayNames}
+	yearSpec       = fieldSpec{fieldYear, 1970, 2099, nil}
+)
+
+func (s fieldSpec) isDayField() bool {
+	return s.name == fieldDayOfMonth || s.name == fieldDayOfWeek
+}
+
+// weekday converts a day of week value in the spec's numbering to a
+// time.Weekday number, where Sunday is 0.
+func (s fieldSpec) weekday(v int) int {
+	return (v - s.min) % 7
+}
+
+// saturday returns Saturday in the spec's weekday numbering.
+func (s fieldSpec) saturday() int {
+	return s.min + int(time.Saturday)
+}
+
+// ParseMode selects which expression layouts ParseCronWithMode accepts.
+// Finer differences between schedulers are described by a Dialect.
+type ParseMode int
+
+const (
+	// ModeStandard accepts the classic 5 field layout:
+	// minute hour day-of-month month day-of-week.
+	ModeStandard ParseMode = iota
+	// ModeQuartz accepts the Quartz/Spring layout, with a leading seconds
+	// field and an optional trailing year field (6 or 7 fields). Exactly one
+	// of the day fields must be '?' and weekdays run from 1 for Sunday.
+	ModeQuartz
+	// ModeEventBridge accepts the AWS EventBridge layout: minute hour
+	// day-of-month month day-of-week year. Exactly one of the day fields
+	// must be '?'.
+	ModeEventBridge
+)
+
+const (
+	macroReboot = "@reboot"
+	macroEvery  = "@every"
+)
+
+// macros maps the predefined schedule macros to their 5 field equivalents.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * SUN",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// hashedMacros are the Jenkins expansions of the macros, which spread jobs
+// out with H instead of running them all at midnight.
+var hashedMacros = map[string]string{
+	"@yearly":   "H H H H *",
+	"@annually": "H H H H *",
+	"@monthly":  "H H H * *",
+	"@weekly":   "H H * * H",
+	"@daily":    "H H * * *",
+	"@midnight": "H H(0-2) * * *",
+	"@hourly":   "H * * * *",
+}
+
 type Expression struct {
-	Minute     string
-	Hour       string
-	DayOfMonth string
-	Month      string
-	DayOfWeek  string
+	// Macro is the predefined macro the expression was written as, e.g.
+	// "@daily" or "@every 1h30m". Calendar macros also have their fields set
+	// to the expanded schedule; @every and @reboot have no fields.
+	Macro string
+	// Interval is the fixed delay between runs of an @every expression.
+	Interval time.Duration
+	// Second is only set for expressions parsed with ModeQuartz.
+	Second     Field
+	Minute     Field
+	Hour       Field
+	DayOfMonth Field
+	Month      Field
+	DayOfWeek  Field
+	// Year is only set for 7 field ModeQuartz and for ModeEventBridge
+	// expressions.
+	Year Field
+	Mode ParseMode
+	// Dialect is the dialect the expression was parsed with.
+	Dialect *Dialect
+	// Location is the time zone named by a CRON_TZ= or TZ= prefix, e.g.
+	// "CRON_TZ=Asia/Kolkata 0 9 * * *". It is nil without a prefix, and Next
+	// then works in the location of the time it is given.
+	Location *time.Location
 }
 
 func ParseCron(expression string) (*Expression, error) {
+	return ParseCronWithMode(expression, ModeStandard)
+}
+
+// ParseCronWithMode parses an expression using the layout selected by mode,
+// accepting every syntax the layout allows. See DialectForMode.
+func ParseCronWithMode(expression string, mode ParseMode) (*Expression, error) {
+	return ParseCronDialect(expression, DialectForMode(mode))
+}
+
+// ParseCronDialect parses an expression written for the given dialect,
+// optionally prefixed with a time zone as CRON_TZ=<zone> or TZ=<zone>.
+// Syntax the dialect does not support is reported by Validate, not here.
+func ParseCronDialect(expression string, d *Dialect) (*Expression, error) {
 	fields := strings.Fields(expression)
-	if fields == nil || len(fields) != 5 {
-		return nil, &ValidationError{"expression", "must have 5 fields"}
+	if len(fields) == 0 || !isTimeZonePrefix(fields[0]) {
+		return parseFields(fields, d)
+	}
+	loc, err := parseTimeZone(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	expr, err := parseFields(fields[1:], d)
+	if err != nil {
+		return nil, err
 	}
+	expr.Location = loc
+	return expr, nil
+}
 
-	return &Expression{
-		fields[0],
-		fields[1],
-		fields[2],
-		fields[3],
-		fields[4],
-	}, nil
+// isTimeZonePrefix reports whether a field is a CRON_TZ= or TZ= prefix.
+func isTimeZonePrefix(field string) bool {
+	return strings.HasPrefix(field, "CRON_TZ=") || strings.HasPrefix(field, "TZ=")
 }
 
-func (c *Expression) Validate() error {
-	validators := []struct {
-		field    string
-		value    string
-		min, max int
-		names    []string
-	}{
-		{"minute", c.Minute, 0, 59, nil},
-		{"hour", c.Hour, 0, 23, nil},
-		{"day of month", c.DayOfMonth, 0, 31, nil},
-		{"month", c.Month, 1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
-		{"day of week", c.DayOfWeek, 0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
-	}
-
-	for _, v := range validators {
-		if err := validateField(v.field, v.value, v.min, v.max, v.names); err != nil {
-			return err
+// parseTimeZone loads the IANA time zone named by a CRON_TZ= or TZ= prefix.
+func parseTimeZone(prefix string) (*time.Location, error) {
+	name := prefix[strings.Index(prefix, "=")+1:]
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" {
+		return nil, &ValidationError{"expression", fmt.Sprintf("unknown time zone %q", name), CodeInvalidTimeZone}
+	}
+	return loc, nil
+}
+
+func parseFields(fields []string, d *Dialect) (*Expression, error) {
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		return parseMacro(fields, d)
+	}
+	specs, err := d.fieldSpecs(len(fields))
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &Expression{Mode: d.Mode, Dialect: d}
+	for i, spec := range specs {
+		f, err := parseField(spec, fields[i])
+		if err != nil {
+			return nil, err
 		}
+		*expr.field(spec.name) = f
 	}
-	return nil
+	return expr, nil
 }
 
-func validateField(field, value string, min, max int, names []string) error {
-	if value == "*" {
-		return nil
+// fieldSpecs returns the layout of an expression with n fields in the
+// dialect. If n is not a valid field count it returns the closest layout
+// along with an error.
+func (d *Dialect) fieldSpecs(n int) ([]fieldSpec, error) {
+	specs := []fieldSpec{minuteSpec, hourSpec, dayOfMonthSpec, monthSpec, d.dayOfWeekSpec()}
+
+	switch d.Mode {
+	case ModeEventBridge:
+		specs = append(specs, yearSpec)
+		if n != 6 {
+			return specs, &ValidationError{"expression", "must have 6 fields", CodeFieldCount}
+		}
+	case ModeQuartz:
+		specs = append([]fieldSpec{secondSpec}, specs...)
+		if n >= 7 {
+			specs = append(specs, yearSpec)
+		}
+		if n != 6 && n != 7 {
+			return specs, &ValidationError{"expression", "must have 6 or 7 fields", CodeFieldCount}
+		}
+	default:
+		if n != 5 {
+			return specs, &ValidationError{"expression", "must have 5 fields", CodeFieldCount}
+		}
 	}
+	return specs, nil
+}
 
-	if strings.Contains(value, "/") {
-		parts := strings.Split(value, "/")
-		if len(parts) != 2 {
-			return &ValidationError{field, "invalid value"}
+func parseMacro(fields []string, d *Dialect) (*Expression, error) {
+	name := strings.ToLower(fields[0])
+	switch name {
+	case macroReboot:
+		if len(fields) != 1 {
+			return nil, &ValidationError{"expression", "@reboot takes no arguments", CodeInvalidMacro}
 		}
-		if parts[0] != "*" {
-			return &ValidationError{field, "must start with a '*'"}
+		return &Expression{Macro: macroReboot, Mode: d.Mode, Dialect: d}, nil
+	case macroEvery:
+		if len(fields) != 2 {
+			return nil, &ValidationError{"expression", "@every requires a single duration, e.g. @every 1h30m", CodeInvalidMacro}
 		}
-		if _, err := strconv.Atoi(parts[1]); err != nil {
-			return &ValidationError{field, "value after '\\' must be a number"}
+		interval, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, &ValidationError{"expression", "invalid @every duration", CodeInvalidMacro}
 		}
-		value = parts[1]
+		return &Expression{Macro: macroEvery + " " + fields[1], Interval: interval, Mode: d.Mode, Dialect: d}, nil
 	}
 
-	if strings.Contains(value, "-") {
-		return validateRange(field, value, min, max, names)
+	expansion, ok := macros[name]
+	if d.HashedMacros {
+		expansion, ok = hashedMacros[name]
+	}
+	if !ok || len(fields) != 1 {
+		return nil, &ValidationError{"expression", "unknown macro " + fields[0], CodeInvalidMacro}
 	}
+	switch d.Mode {
+	case ModeQuartz:
+		expansion = quartzExpansion(expansion)
+	case ModeEventBridge:
+		expansion = strings.TrimPrefix(quartzExpansion(expansion), "0 ") + " *"
+	}
+	expr, err := ParseCronDialect(expansion, d)
+	if err != nil {
+		return nil, err
+	}
+	expr.Macro = name
+	return expr, nil
+}
+
+// quartzExpansion rewrites a 5 field macro expansion into the Quartz
+// layout, adding a seconds field and a '?' in the unused day field.
+func quartzExpansion(expansion string) string {
+	fields := strings.Fields(expansion)
+	if fields[4] == "*" {
+		fields[4] = "?"
+	} else {
+		fields[2] = "?"
+	}
+	return "0 " + strings.Join(fields, " ")
+}
+
+// IsReboot reports whether the expression is @reboot, which runs once at
+// startup and so has no scheduled run times.
+func (c *Expression) IsReboot() bool { return c.Macro == macroReboot }
+
+// IsInterval reports whether the expression is an @every interval schedule.
+func (c *Expression) IsInterval() bool { return c.Interval != 0 }
+
+func (c *Expression) field(name string) *Field {
+	switch name {
+	case fieldSecond:
+		return &c.Second
+	case fieldMinute:
+		return &c.Minute
+	case fieldHour:
+		return &c.Hour
+	case fieldDayOfMonth:
+		return &c.DayOfMonth
+	case fieldMonth:
+		return &c.Month
+	case fieldDayOfWeek:
+		return &c.DayOfWeek
+	case fieldYear:
+		return &c.Year
+	}
+	return nil
+}
 
-	if strings.Contains(value, ",") {
-		if err := validateList(field, value, min, max, names); err != nil {
-			return &ValidationError{field, "invalid value in list"}
+// Fields returns the fields present in the expression in the order they
+// are written.
+func (c *Expression) Fields() []Field {
+	var fields []Field
+	for _, f := range []Field{c.Second, c.Minute, c.Hour, c.DayOfMonth, c.Month, c.DayOfWeek, c.Year} {
+		if f.IsSet() {
+			fields = append(fields, f)
 		}
-		return nil
 	}
+	return fields
+}
 
-	if value == "?" && (field == "day of month" || field == "day of week") {
-		return nil
+func (c *Expression) String() string {
+	if c.Macro != "" {
+		return c.timeZonePrefix() + c.Macro
+	}
+	fields := c.Fields()
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.String()
 	}
+	return c.timeZonePrefix() + strings.Join(parts, " ")
+}
 
-	if value == "L" && (field == "day of month" || field == "day of week") {
+// timeZonePrefix returns the CRON_TZ= prefix for the expression's
+// location, followed by a space, or "" if it has none.
+func (c *Expression) timeZonePrefix() string {
+	if c.Location == nil {
+		return ""
+	}
+	return "CRON_TZ=" + c.Location.String() + " "
+}
+
+func (c *Expression) Validate() error {
+	if err := c.dialect().validateMacro(c.Macro); err != nil {
+		return err
+	}
+	if c.IsInterval() && c.Interval < time.Second {
+		return &ValidationError{"expression", "@every duration must be at least 1s", CodeInvalidMacro}
+	}
+	if c.IsInterval() || c.IsReboot() {
 		return nil
 	}
 
-	if strings.HasSuffix(value, "W") && field == "day of month" {
-		dayStr := strings.TrimSuffix(value, "W")
-		day, err := strconv.Atoi(dayStr)
-		if err != nil || day < 1 || day > 31 {
-			return &ValidationError{field, "invalid weekday value"}
+	for _, f := range c.Fields() {
+		if err := validateNode(f.spec, f.Root); err != nil {
+			return err
 		}
-		return nil
+	}
+	return c.dialect().validate(c)
+}
+
+// dialect returns the expression's dialect, defaulting to the permissive
+// dialect of its mode for expressions built without one.
+func (c *Expression) dialect() *Dialect {
+	if c.Dialect != nil {
+		return c.Dialect
+	}
+	return DialectForMode(c.Mode)
+}
+
+// validateDayFields checks the Quartz rule that exactly one of the day
+// fields is '?'.
+func validateDayFields(dayOfMonth, dayOfWeek Field) error {
+	_, domAny := dayOfMonth.Root.(NoSpecific)
+	_, dowAny := dayOfWeek.Root.(NoSpecific)
+	if domAny == dowAny {
+		return &ValidationError{fieldDayOfWeek, "'?' must be used in exactly one of day of month or day of week", CodeDayFieldConflict}
+	}
+	return nil
+}
+
+func parseField(spec fieldSpec, value string) (Field, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 1 {
+		root, err := parseItem(spec, value)
+		if err != nil {
+			return Field{}, err
+		}
+		return Field{Raw: value, Root: root, spec: spec}, nil
 	}
 
-	if strings.Contains(value, "#") && field == "day of week" {
+	list := List{Items: make([]Node, len(parts))}
+	for i, part := range parts {
+		item, err := parseItem(spec, part)
+		if err != nil {
+			return Field{}, &ValidationError{spec.name, "invalid value in list", CodeInvalidList}
+		}
+		list.Items[i] = item
+	}
+	return Field{Raw: value, Root: list, spec: spec}, nil
+}
+
+func parseItem(spec fieldSpec, value string) (Node, error) {
+	switch {
+	case value == "*":
+		return Wildcard{}, nil
+	case value == "?" && spec.isDayField():
+		return NoSpecific{}, nil
+	case value == "L" && spec.isDayField():
+		return Last{}, nil
+	case strings.HasPrefix(value, "H") && spec.name != fieldYear:
+		return parseHash(spec, value)
+	case value == "LW" && spec.name == fieldDayOfMonth:
+		return LastWeekday{}, nil
+	case strings.HasPrefix(value, "L-") && spec.name == fieldDayOfMonth:
+		offset, err := strconv.Atoi(strings.TrimPrefix(value, "L-"))
+		if err != nil {
+			return nil, &ValidationError{spec.name, "invalid offset from the last day", CodeInvalidLast}
+		}
+		return Last{offset}, nil
+	case strings.HasSuffix(value, "L") && spec.name == fieldDayOfWeek:
+		weekday, err := parseValue(spec, strings.TrimSuffix(value, "L"))
+		if err != nil {
+			return nil, &ValidationError{spec.name, "invalid last weekday of month", CodeInvalidLast}
+		}
+		return LastOccurrence{weekday.Value, weekday.Name}, nil
+	case strings.Contains(value, "/"):
+		return parseStep(spec, value)
+	case strings.HasSuffix(value, "W") && spec.name == fieldDayOfMonth:
+		day, err := strconv.Atoi(strings.TrimSuffix(value, "W"))
+		if err != nil {
+			return nil, &ValidationError{spec.name, "invalid weekday value", CodeInvalidNearestWeekday}
+		}
+		return NearestWeekday{day}, nil
+	case strings.Contains(value, "#") && spec.name == fieldDayOfWeek:
 		parts := strings.Split(value, "#")
 		if len(parts) != 2 {
-			return &ValidationError{field, "invalid nth weekday of month"}
+			return nil, &ValidationError{spec.name, "invalid nth weekday of month", CodeInvalidNthWeekday}
 		}
-		weekday, err1 := strconv.Atoi(parts[0])
+		weekday, err1 := parseValue(spec, parts[0])
 		n, err2 := strconv.Atoi(parts[1])
-		if err1 != nil || err2 != nil || weekday < 1 || weekday > 7 || n < 1 || n > 5 {
-			return &ValidationError{field, "invalid nth weekday of month"}
+		if err1 != nil || err2 != nil {
+			return nil, &ValidationError{spec.name, "invalid nth weekday of month", CodeInvalidNthWeekday}
 		}
-		return nil
+		return NthWeekday{weekday.Value, n, weekday.Name}, nil
+	case strings.Contains(value, "-"):
+		return parseRange(spec, value)
 	}
 
-	if _, err := strconv.Atoi(value); err == nil {
-		return validateNumber(field, value, min, max)
-	} else {
-		for _, name := range names {
-			if strings.EqualFold(value, name) {
-				return nil
-			}
+	v, err := parseValue(spec, value)
+	if err != nil {
+		return nil, &ValidationError{spec.name, "invalid value", CodeInvalidValue}
+	}
+	return v, nil
+}
+
+// parseStep parses a stepped item. The base may be '*', a range such as
+// 10-50, or a single start value, where 0/15 means 0-max/15.
+func parseStep(spec fieldSpec, value string) (Node, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return nil, &ValidationError{spec.name, "invalid value", CodeInvalidStep}
+	}
+	interval, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, &ValidationError{spec.name, "invalid step value", CodeInvalidStep}
+	}
+
+	var base Node
+	switch {
+	case parts[0] == "*":
+		base = Wildcard{}
+	case strings.Contains(parts[0], "-"):
+		if base, err = parseRange(spec, parts[0]); err != nil {
+			return nil, err
+		}
+	default:
+		if base, err = parseValue(spec, parts[0]); err != nil {
+			return nil, &ValidationError{spec.name, "step must start with '*', a range or a value", CodeInvalidStep}
 		}
 	}
+	return Step{base, interval}, nil
+}
 
-	return &ValidationError{Field: field, Message: "invalid value"}
+// parseHash parses Jenkins' H, H(a-b), H/n and H(a-b)/n.
+func parseHash(spec fieldSpec, value string) (Node, error) {
+	var h Hash
+	rest := strings.TrimPrefix(value, "H")
+	if strings.HasPrefix(rest, "(") {
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return nil, &ValidationError{spec.name, "missing ')' after H(", CodeInvalidHash}
+		}
+		r, err := parseRange(spec, rest[1:end])
+		if err != nil {
+			return nil, err
+		}
+		h.Range, rest = r, rest[end+1:]
+	}
+	if rest != "" {
+		interval, err := strconv.Atoi(strings.TrimPrefix(rest, "/"))
+		if !strings.HasPrefix(rest, "/") || err != nil {
+			return nil, &ValidationError{spec.name, "H must be followed by a range in parentheses or a step", CodeInvalidHash}
+		}
+		if interval < 1 {
+			return nil, &ValidationError{spec.name, "step must be at least 1", CodeInvalidStep}
+		}
+		h.Interval = interval
+	}
+	return h, nil
 }
 
-func validateRange(field, value string, min, max int, names []string) error {
+func parseRange(spec fieldSpec, value string) (Node, error) {
 	parts := strings.Split(value, "-")
 	if len(parts) != 2 {
-		return &ValidationError{field, "incomplete range"}
+		return nil, &ValidationError{spec.name, "incomplete range", CodeInvalidRange}
 	}
 
-	var start, end int
-	var err error
-
-	start, err = strconv.Atoi(parts[0])
+	start, err := parseValue(spec, parts[0])
 	if err != nil {
-		start = findNameIndex(parts[0], names)
-		if start == -1 {
-			return &ValidationError{field, "invalid range start"}
-		}
-		start++ // Adjust index to 1-based for consistency with numeric values
+		return nil, &ValidationError{spec.name, "invalid range start", CodeInvalidRange}
 	}
-
-	// Check if end is a number or a name
-	end, err = strconv.Atoi(parts[1])
+	end, err := parseValue(spec, parts[1])
 	if err != nil {
-		end = findNameIndex(parts[1], names)
-		if end == -1 {
-			return &ValidationError{field, "invalid range end"}
-		}
-		end++ // Adjust index to 1-based for consistency with numeric values
+		return nil, &ValidationError{spec.name, "invalid range end", CodeInvalidRange}
 	}
+	return Range{start, end}, nil
+}
 
-	if start < min || start > max || end < min || end > max || end < start {
-		return &ValidationError{field, "invalid range"}
+// parseValue parses a number or, if the field has names, a name.
+func parseValue(spec fieldSpec, value string) (Value, error) {
+	if num, err := strconv.Atoi(value); err == nil {
+		return Value{Value: num}, nil
 	}
+	if i := findNameIndex(value, spec.names); i != -1 {
+		return Value{Value: spec.min + i, Name: strings.ToUpper(value)}, nil
+	}
+	return Value{}, fmt.Errorf("%q is not a number or a known name", value)
+}
 
-	return nil
+// validateField parses and validates a single field value.
+func validateField(field, value string, min, max int, names []string) error {
+	spec := fieldSpec{field, min, max, names}
+	f, err := parseField(spec, value)
+	if err != nil {
+		return err
+	}
+	return validateNode(spec, f.Root)
 }
 
-func validateList(field, value string, min, max int, names []string) error {
-	parts := strings.Split(value, ",")
-	for _, part := range parts {
-		if err := validateField(field, part, min, max, names); err != nil {
-			return &ValidationError{field, err.Error()}
+func validateNode(spec fieldSpec, node Node) error {
+	switch n := node.(type) {
+	case Value:
+		return validateNumber(spec, n.Value)
+	case Range:
+		if !inRange(spec, n.Start.Value) || !inRange(spec, n.End.Value) || n.End.Value < n.Start.Value {
+			return &ValidationError{spec.name, "invalid range", CodeInvalidRange}
+		}
+	case Step:
+		if n.Interval < 1 {
+			return &ValidationError{spec.name, "step must be at least 1", CodeInvalidStep}
+		}
+		// A year step is a number of years, not a year, so it is bounded by
+		// the field's span rather than its range.
+		if spec.name == fieldYear {
+			if span := spec.max - spec.min; n.Interval > span {
+				return &ValidationError{spec.name, fmt.Sprintf("step must be between 1 and %d", span), CodeInvalidStep}
+			}
+		} else if err := validateNumber(spec, n.Interval); err != nil {
+			return err
+		}
+		return validateNode(spec, n.Base)
+	case List:
+		for _, item := range n.Items {
+			if err := validateNode(spec, item); err != nil {
+				return &ValidationError{spec.name, "invalid value in list", CodeInvalidList}
+			}
+		}
+	case NearestWeekday:
+		if n.Day < 1 || n.Day > 31 {
+			return &ValidationError{spec.name, "invalid weekday value", CodeInvalidNearestWeekday}
+		}
+	case NthWeekday:
+		if !inRange(spec, n.Weekday) || n.N < 1 || n.N > 5 {
+			return &ValidationError{spec.name, "invalid nth weekday of month", CodeInvalidNthWeekday}
+		}
+	case Last:
+		if n.Offset < 0 || n.Offset > 30 {
+			return &ValidationError{spec.name, "offset from the last day must be between 0 and 30", CodeInvalidLast}
+		}
+	case LastOccurrence:
+		if !inRange(spec, n.Weekday) {
+			return &ValidationError{spec.name, "invalid last weekday of month", CodeInvalidLast}
+		}
+	case Hash:
+		if n.Range != nil {
+			if err := validateNode(spec, n.Range); err != nil {
+				return err
+			}
+		}
+		if min, max := n.bounds(spec); n.Interval > max-min+1 {
+			return &ValidationError{spec.name, fmt.Sprintf("step must not exceed the %d values H chooses from", max-min+1), CodeInvalidHash}
 		}
 	}
 	return nil
 }
 
-func validateNumber(field, value string, min, max int) error {
-	num, err := strconv.Atoi(value)
-	if err != nil || num < min || num > max {
-		return &ValidationError{field, fmt.Sprintf("value must be between %d and %d", min, max)}
+func inRange(spec fieldSpec, value int) bool {
+	return value >= spec.min && value <= spec.max
+}
+
+func validateNumber(spec fieldSpec, value int) error {
+	if !inRange(spec, value) {
+		return &ValidationError{spec.name, fmt.Sprintf("value must be between %d and %d", spec.min, spec.max), CodeOutOfRange}
 	}
 	return nil
 }
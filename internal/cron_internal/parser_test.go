@@ -2,6 +2,7 @@ package cron_internal
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +30,13 @@ func TestParseCron(t *testing.T) {
 		{"Invalid W usage", "0 5 32W * *", true, "day of month", "invalid weekday value"},
 		{"Valid with #", "0 5 * * 2#1", false, "", ""},
 		{"Invalid # usage", "0 5 * * 8#1", true, "day of week", "invalid nth weekday of month"},
+		{"Valid with LW", "0 5 LW * *", false, "", ""},
+		{"Valid with L-n", "0 5 L-3 * *", false, "", ""},
+		{"Invalid L-n offset", "0 5 L-31 * *", true, "day of month", "offset from the last day must be between 0 and 30"},
+		{"Invalid L-n usage", "0 5 L-x * *", true, "day of month", "invalid offset from the last day"},
+		{"L-n in day of week", "0 5 * * L-2", true, "day of week", "invalid range start"},
+		{"Valid with nL", "0 5 * * 5L", false, "", ""},
+		{"Invalid nL usage", "0 5 * * 8L", true, "day of week", "invalid last weekday of month"},
 	}
 
 	for _, tt := range tests {
@@ -76,6 +84,13 @@ func TestValidateField(t *testing.T) {
 		{"Valid # usage", "day of week", "2#1", 0, 7, nil, false, "", ""},
 		{"Invalid # usage", "day of week", "8#1", 0, 7, nil, true, "day of week", "invalid nth weekday of month"},
 		{"Valid month name", "month", "JAN", 1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}, false, "", ""},
+		{"Valid stepped range", "minute", "10-50/10", 0, 59, nil, false, "", ""},
+		{"Valid stepped start", "minute", "3/15", 0, 59, nil, false, "", ""},
+		{"Valid stepped range in list", "minute", "1-5/2,30", 0, 59, nil, false, "", ""},
+		{"Invalid item beside stepped range", "hour", "1-5/2,30", 0, 23, nil, true, "hour", "invalid value in list"},
+		{"Invalid stepped range", "minute", "50-10/10", 0, 59, nil, true, "minute", "invalid range"},
+		{"Invalid stepped start", "minute", "60/15", 0, 59, nil, true, "minute", "value must be between 0 and 59"},
+		{"Invalid step base", "minute", "L/15", 0, 59, nil, true, "minute", "step must start with '*', a range or a value"},
 		{"Invalid month name", "month", "FOO", 1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}, true, "month", "invalid value"},
 	}
 
@@ -97,3 +112,161 @@ func TestValidateField(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCronTree(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		spec  fieldSpec
+		want  Node
+	}{
+		{"Wildcard", "*", minuteSpec, Wildcard{}},
+		{"Number", "5", minuteSpec, Value{Value: 5}},
+		{"Name", "mon", dayOfWeekSpec, Value{Value: 1, Name: "MON"}},
+		{"Range", "JAN-MAR", monthSpec, Range{Value{1, "JAN"}, Value{3, "MAR"}}},
+		{"Step", "*/15", minuteSpec, Step{Wildcard{}, 15}},
+		{"Last", "L", dayOfMonthSpec, Last{}},
+		{"Nearest weekday", "15W", dayOfMonthSpec, NearestWeekday{15}},
+		{"Nth weekday", "FRI#2", dayOfWeekSpec, NthWeekday{5, 2, "FRI"}},
+		{"Last with offset", "L-3", dayOfMonthSpec, Last{3}},
+		{"Last weekday", "LW", dayOfMonthSpec, LastWeekday{}},
+		{"Last occurrence", "FRIL", dayOfWeekSpec, LastOccurrence{5, "FRI"}},
+		{"Hash", "H", minuteSpec, Hash{}},
+		{"Hash in a range", "H(0-7)", hourSpec, Hash{Range{Value{Value: 0}, Value{Value: 7}}, 0}},
+		{"Hashed step", "H/15", minuteSpec, Hash{nil, 15}},
+		{"No specific", "?", dayOfWeekSpec, NoSpecific{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseField(tt.spec, tt.field)
+			if err != nil {
+				t.Fatalf("parseField() error = %v", err)
+			}
+			if f.Root != tt.want {
+				t.Errorf("parseField() = %#v, want %#v", f.Root, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		spec  fieldSpec
+		want  []int
+	}{
+		{"Step", "*/20", minuteSpec, []int{0, 20, 40}},
+		{"List with range", "1,3-5,10", hourSpec, []int{1, 3, 4, 5, 10}},
+		{"Weekday names", "MON-FRI", dayOfWeekSpec, []int{1, 2, 3, 4, 5}},
+		{"Sunday as 7", "5,7", dayOfWeekSpec, []int{0, 5}},
+		{"Month names", "JAN,JUN,DEC", monthSpec, []int{1, 6, 12}},
+		{"Calendar dependent", "L", dayOfMonthSpec, nil},
+		{"Stepped range", "10-50/10", minuteSpec, []int{10, 20, 30, 40, 50}},
+		{"Stepped start", "3/15", minuteSpec, []int{3, 18, 33, 48}},
+		{"Stepped items in list", "1-5/2,30", minuteSpec, []int{1, 3, 5, 30}},
+		{"Stepped weekday start", "1/2", dayOfWeekSpec, []int{1, 3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseField(tt.spec, tt.field)
+			if err != nil {
+				t.Fatalf("parseField() error = %v", err)
+			}
+			got := f.Values().Values()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Values() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Values() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronQuartz(t *testing.T) {
+	tests := []struct {
+		name         string
+		expression   string
+		wantErr      bool
+		errorField   string
+		errorMessage string
+	}{
+		{"Six fields", "0 0 12 * * ?", false, "", ""},
+		{"Seven fields", "0 15 10 ? * MON-FRI 2030", false, "", ""},
+		{"Five fields", "0 12 * * ?", true, "expression", "must have 6 or 7 fields"},
+		{"Invalid second", "60 0 12 * * ?", true, "second", "value must be between 0 and 59"},
+		{"Invalid year", "0 0 12 * * ? 2100", true, "year", "value must be between 1970 and 2099"},
+		{"Year step", "0 0 0 1 1 ? */2", false, "", ""},
+		{"Year step from a start", "0 0 0 1 1 ? 2024/2", false, "", ""},
+		{"Year range step", "0 0 0 1 1 ? 2024-2030/3", false, "", ""},
+		{"Year step too large", "0 0 0 1 1 ? */130", true, "year", "step must be between 1 and 129"},
+		{"No question mark", "0 0 12 * * *", true, "day of week", "'?' must be used in exactly one of day of month or day of week"},
+		{"Two question marks", "0 0 12 ? * ?", true, "day of week", "'?' must be used in exactly one of day of month or day of week"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCronWithMode(tt.expression, ModeQuartz)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCronWithMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Field != tt.errorField || cronErr.Message != tt.errorMessage {
+					t.Errorf("ValidateCronWithMode() error = %v, want field %v, message %v", err, tt.errorField, tt.errorMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronMacros(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		mode       ParseMode
+		wantFields string
+		wantErr    bool
+	}{
+		{"Yearly", "@yearly", ModeStandard, "0 0 1 1 *", false},
+		{"Annually", "@annually", ModeStandard, "0 0 1 1 *", false},
+		{"Monthly", "@monthly", ModeStandard, "0 0 1 * *", false},
+		{"Weekly", "@weekly", ModeStandard, "0 0 * * SUN", false},
+		{"Daily", "@DAILY", ModeStandard, "0 0 * * *", false},
+		{"Hourly", "@hourly", ModeStandard, "0 * * * *", false},
+		{"Quartz daily", "@daily", ModeQuartz, "0 0 0 * * ?", false},
+		{"Quartz weekly", "@weekly", ModeQuartz, "0 0 0 ? * SUN", false},
+		{"Reboot", "@reboot", ModeStandard, "", false},
+		{"Every", "@every 1h30m", ModeStandard, "", false},
+		{"Every too short", "@every 10ms", ModeStandard, "", true},
+		{"Every without duration", "@every", ModeStandard, "", true},
+		{"Unknown macro", "@sometimes", ModeStandard, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronWithMode(tt.expression, tt.mode)
+			if err == nil {
+				err = expr.Validate()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCronWithMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var fields []string
+			for _, f := range expr.Fields() {
+				fields = append(fields, f.String())
+			}
+			if got := strings.Join(fields, " "); got != tt.wantFields {
+				t.Errorf("Fields() = %q, want %q", got, tt.wantFields)
+			}
+		})
+	}
+}
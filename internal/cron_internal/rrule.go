@@ -0,0 +1,297 @@
+package cron_internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rruleWeekdays are the RFC 5545 weekday codes, indexed from Sunday.
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RRules converts the expression to RFC 5545 recurrence rules, e.g.
+// "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0;BYSECOND=0".
+//
+// The rules leave the start to the calendar's DTSTART. When both day fields
+// are restricted, cron fires if either matches, so one rule is returned per
+// day field; the recurrence set is their union. @every becomes an interval
+// rule. Years, W, LW and @reboot have no RRULE form and are rejected with
+// CodeUnsupported.
+func (c *Expression) RRules() ([]string, error) {
+	switch {
+	case c.IsReboot():
+		return nil, &ValidationError{"expression", "@reboot has no recurrence rule", CodeUnsupported}
+	case c.IsInterval():
+		return intervalRRule(c.Interval)
+	case c.Year.IsSet() && !c.Year.IsWildcard():
+		return nil, &ValidationError{fieldYear, "a year field has no recurrence rule", CodeUnsupported}
+	}
+	if walk(c.DayOfMonth.Root, func(n Node) bool { _, ok := n.(NearestWeekday); return ok }) {
+		return nil, &ValidationError{fieldDayOfMonth, "'W' has no recurrence rule", CodeUnsupported}
+	}
+	if walk(c.DayOfMonth.Root, func(n Node) bool { _, ok := n.(LastWeekday); return ok }) {
+		return nil, &ValidationError{fieldDayOfMonth, "'LW' has no recurrence rule", CodeUnsupported}
+	}
+
+	second := c.Second
+	if !second.IsSet() {
+		second, _ = parseField(secondSpec, "0")
+	}
+	hourFull, minuteFull := c.Hour.Values().IsFull(), c.Minute.Values().IsFull()
+	freq := "DAILY"
+	switch {
+	case hourFull && minuteFull:
+		freq = "MINUTELY"
+	case hourFull:
+		freq = "HOURLY"
+	}
+
+	// BYHOUR, BYMINUTE and BYSECOND expand finer than FREQ and are listed
+	// in full; coarser ones would otherwise come from DTSTART.
+	rule := func(freq, day string) string {
+		parts := []string{"FREQ=" + freq}
+		if day != "" {
+			parts = append(parts, day)
+		}
+		if !c.Month.IsWildcard() {
+			parts = append(parts, "BYMONTH="+rruleValues(c.Month.Values()))
+		}
+		if freq != "MINUTELY" && freq != "HOURLY" {
+			parts = append(parts, "BYHOUR="+rruleValues(c.Hour.Values()))
+		}
+		if freq != "MINUTELY" {
+			parts = append(parts, "BYMINUTE="+rruleValues(c.Minute.Values()))
+		}
+		return strings.Join(append(parts, "BYSECOND="+rruleValues(second.Values())), ";")
+	}
+
+	var rules []string
+	if !c.DayOfMonth.IsWildcard() {
+		rules = append(rules, rule(freq, "BYMONTHDAY="+rruleMonthDays(c.DayOfMonth)))
+	}
+	if !c.DayOfWeek.IsWildcard() {
+		// Ordinal weekdays such as 2MO are only allowed in MONTHLY rules.
+		weekdayFreq := freq
+		if walk(c.DayOfWeek.Root, func(n Node) bool {
+			switch n.(type) {
+			case NthWeekday, LastOccurrence:
+				return true
+			}
+			return false
+		}) {
+			weekdayFreq = "MONTHLY"
+		}
+		rules = append(rules, rule(weekdayFreq, "BYDAY="+rruleWeekdayList(c.DayOfWeek)))
+	}
+	if len(rules) == 0 {
+		rules = []string{rule(freq, "")}
+	}
+	return rules, nil
+}
+
+func intervalRRule(interval time.Duration) ([]string, error) {
+	for _, u := range []struct {
+		freq string
+		unit time.Duration
+	}{{"DAILY", 24 * time.Hour}, {"HOURLY", time.Hour}, {"MINUTELY", time.Minute}, {"SECONDLY", time.Second}} {
+		if interval%u.unit == 0 {
+			return []string{fmt.Sprintf("FREQ=%s;INTERVAL=%d", u.freq, interval/u.unit)}, nil
+		}
+	}
+	return nil, &ValidationError{"expression", "@every intervals must be whole seconds", CodeUnsupported}
+}
+
+func rruleValues(set ValueSet) string {
+	values := set.Values()
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+// rruleMonthDays lists the days of month, writing L as -1 and L-n as
+// -(n+1).
+func rruleMonthDays(f Field) string {
+	days := rruleValues(f.Values())
+	walk(f.Root, func(n Node) bool {
+		if n, ok := n.(Last); ok {
+			if days != "" {
+				days += ","
+			}
+			days += strconv.Itoa(-n.Offset - 1)
+		}
+		return false
+	})
+	return days
+}
+
+// rruleWeekdayList lists the weekdays, writing d#n as nXX, e.g. 2MO, and dL
+// as -1XX.
+func rruleWeekdayList(f Field) string {
+	var items []string
+	for _, v := range f.Values().Values() {
+		items = append(items, rruleWeekdays[v])
+	}
+	walk(f.Root, func(n Node) bool {
+		switch n := n.(type) {
+		case NthWeekday:
+			items = append(items, strconv.Itoa(n.N)+rruleWeekdays[f.spec.weekday(n.Weekday)])
+		case LastOccurrence:
+			items = append(items, "-1"+rruleWeekdays[f.spec.weekday(n.Weekday)])
+		}
+		return false
+	})
+	return strings.Join(items, ",")
+}
+
+// ParseRRule converts an RFC 5545 recurrence rule, with or without the
+// "RRULE:" prefix, into an expression in the Quartz layout. Parts that
+// RFC 5545 takes from DTSTART default to midnight, so FREQ=DAILY fires at
+// 00:00:00. A plain interval such as FREQ=MINUTELY;INTERVAL=15 becomes
+// "@every 15m".
+//
+// Rules that cron cannot express are rejected with CodeUnsupported: COUNT,
+// UNTIL, BYSETPOS, BYYEARDAY, BYWEEKNO, negative ordinals, intervals
+// combined with BY parts, and rules restricting both BYMONTHDAY and BYDAY,
+// which RFC 5545 requires to match together.
+func ParseRRule(rule string) (*Expression, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, &ValidationError{"expression", fmt.Sprintf("invalid rule part %q", part), CodeInvalidValue}
+		}
+		parts[strings.ToUpper(kv[0])] = strings.ToUpper(kv[1])
+	}
+
+	freq := parts["FREQ"]
+	units := map[string]time.Duration{"SECONDLY": time.Second, "MINUTELY": time.Minute, "HOURLY": time.Hour, "DAILY": 24 * time.Hour}
+	if _, ok := units[freq]; !ok && freq != "WEEKLY" && freq != "MONTHLY" && freq != "YEARLY" {
+		return nil, &ValidationError{"expression", fmt.Sprintf("unknown FREQ %q", freq), CodeInvalidValue}
+	}
+	for name := range parts {
+		switch name {
+		case "FREQ", "INTERVAL", "WKST", "BYMONTH", "BYMONTHDAY", "BYDAY", "BYHOUR", "BYMINUTE", "BYSECOND":
+		default:
+			return nil, &ValidationError{"expression", name + " cannot be expressed in cron", CodeUnsupported}
+		}
+	}
+
+	if interval, ok := parts["INTERVAL"]; ok && interval != "1" {
+		n, err := strconv.Atoi(interval)
+		if err != nil || n < 1 {
+			return nil, &ValidationError{"expression", "INTERVAL must be a positive number", CodeInvalidValue}
+		}
+		unit, ok := units[freq]
+		for name := range parts {
+			ok = ok && !strings.HasPrefix(name, "BY")
+		}
+		if !ok {
+			return nil, &ValidationError{"expression", "INTERVAL with BY parts or FREQ=" + freq + " cannot be expressed in cron", CodeUnsupported}
+		}
+		return ParseCronWithMode(macroEvery+" "+shortDuration(time.Duration(n)*unit), ModeQuartz)
+	}
+
+	field := func(name, free string) string {
+		if v, ok := parts[name]; ok {
+			return v
+		}
+		return free
+	}
+	fromFreq := func(freqs ...string) string {
+		for _, f := range freqs {
+			if f == freq {
+				return "*"
+			}
+		}
+		return "0"
+	}
+	second := field("BYSECOND", fromFreq("SECONDLY"))
+	minute := field("BYMINUTE", fromFreq("SECONDLY", "MINUTELY"))
+	hour := field("BYHOUR", fromFreq("SECONDLY", "MINUTELY", "HOURLY"))
+	month := field("BYMONTH", "*")
+
+	dayOfMonth, dayOfWeek := "*", "?"
+	monthDays, hasMonthDays := parts["BYMONTHDAY"]
+	weekdays, hasWeekdays := parts["BYDAY"]
+	switch {
+	case hasMonthDays && hasWeekdays:
+		return nil, &ValidationError{fieldDayOfWeek, "BYMONTHDAY with BYDAY matches both, which cron cannot express", CodeUnsupported}
+	case hasMonthDays:
+		days := strings.Split(monthDays, ",")
+		for i, day := range days {
+			if strings.HasPrefix(day, "-") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n < -31 {
+					return nil, &ValidationError{fieldDayOfMonth, fmt.Sprintf("invalid BYMONTHDAY %q", day), CodeInvalidValue}
+				}
+				days[i] = Last{-n - 1}.String()
+			}
+		}
+		dayOfMonth = strings.Join(days, ",")
+	case hasWeekdays:
+		var err error
+		if dayOfWeek, err = parseRRuleWeekdays(weekdays, freq, parts["BYMONTH"] != ""); err != nil {
+			return nil, err
+		}
+		dayOfMonth = "?"
+	case freq == "WEEKLY" || freq == "MONTHLY" || freq == "YEARLY":
+		return nil, &ValidationError{"expression", "FREQ=" + freq + " needs BYDAY or BYMONTHDAY, since the day would come from DTSTART", CodeUnsupported}
+	}
+
+	expr, err := ParseCronWithMode(strings.Join([]string{second, minute, hour, dayOfMonth, month, dayOfWeek}, " "), ModeQuartz)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseRRuleWeekdays rewrites a BYDAY list such as "MO,WE", "2TU" or "-1FR"
+// in cron syntax, naming the weekdays so that the result reads the same in
+// every dialect. Ordinals are only meaningful within a month.
+func parseRRuleWeekdays(byDay, freq string, byMonth bool) (string, error) {
+	items := strings.Split(byDay, ",")
+	for i, item := range items {
+		code := item
+		if len(item) > 2 {
+			code = item[len(item)-2:]
+		}
+		index := findNameIndex(code, rruleWeekdays)
+		if index < 0 {
+			return "", &ValidationError{fieldDayOfWeek, fmt.Sprintf("invalid BYDAY %q", item), CodeInvalidValue}
+		}
+		items[i] = weekdayNames[index]
+		if len(item) == 2 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(item[:len(item)-2], "+"))
+		if err != nil || n < -1 || n == 0 || n > 5 || !(freq == "MONTHLY" || freq == "YEARLY" && byMonth) {
+			return "", &ValidationError{fieldDayOfWeek, fmt.Sprintf("BYDAY %q cannot be expressed in cron", item), CodeUnsupported}
+		}
+		if n == -1 {
+			items[i] += "L"
+			continue
+		}
+		items[i] += "#" + strconv.Itoa(n)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// shortDuration formats d without trailing zero units, e.g. 1h rather than
+// 1h0m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
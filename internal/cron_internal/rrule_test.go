@@ -0,0 +1,121 @@
+package cron_internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []string
+		wantCode   string
+	}{
+		{"Weekdays", "0 9 * * 1-5", []string{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0;BYSECOND=0"}, ""},
+		{"Every 15 minutes", "*/15 * * * *", []string{"FREQ=HOURLY;BYMINUTE=0,15,30,45;BYSECOND=0"}, ""},
+		{"Every minute", "* * * * *", []string{"FREQ=MINUTELY;BYSECOND=0"}, ""},
+		{"Monthly in some months", "30 6 1 1,7 *", []string{"FREQ=DAILY;BYMONTHDAY=1;BYMONTH=1,7;BYHOUR=6;BYMINUTE=30;BYSECOND=0"}, ""},
+		{"Last day", "0 0 L * *", []string{"FREQ=DAILY;BYMONTHDAY=-1;BYHOUR=0;BYMINUTE=0;BYSECOND=0"}, ""},
+		{"Nth weekday", "0 0 * * MON#2", []string{"FREQ=MONTHLY;BYDAY=2MO;BYHOUR=0;BYMINUTE=0;BYSECOND=0"}, ""},
+		{"Days before month end", "0 0 L-2 * *", []string{"FREQ=DAILY;BYMONTHDAY=-3;BYHOUR=0;BYMINUTE=0;BYSECOND=0"}, ""},
+		{"Last Friday", "0 0 * * 5L", []string{"FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=0;BYMINUTE=0;BYSECOND=0"}, ""},
+		{"Last weekday", "0 0 LW * *", nil, CodeUnsupported},
+		{"Both day fields", "0 0 13 * 5", []string{
+			"FREQ=DAILY;BYMONTHDAY=13;BYHOUR=0;BYMINUTE=0;BYSECOND=0",
+			"FREQ=DAILY;BYDAY=FR;BYHOUR=0;BYMINUTE=0;BYSECOND=0",
+		}, ""},
+		{"Interval", "@every 90m", []string{"FREQ=MINUTELY;INTERVAL=90"}, ""},
+		{"Reboot", "@reboot", nil, CodeUnsupported},
+		{"Nearest weekday", "0 0 15W * *", nil, CodeUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			got, err := expr.RRules()
+			if tt.wantCode != "" {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+					t.Fatalf("RRules() = %v, %v, want code %s", got, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RRules() error = %v", err)
+			}
+			if strings.Join(got, " | ") != strings.Join(tt.want, " | ") {
+				t.Errorf("RRules() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		want     string
+		wantCode string
+	}{
+		{"Weekdays", "RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0", "0 0 9 ? * MON,TUE,WED,THU,FRI", ""},
+		{"Weekly", "FREQ=WEEKLY;BYDAY=SU;BYHOUR=18;BYMINUTE=30", "0 30 18 ? * SUN", ""},
+		{"Second Tuesday", "FREQ=MONTHLY;BYDAY=2TU;BYHOUR=10", "0 0 10 ? * TUE#2", ""},
+		{"Last day", "FREQ=MONTHLY;BYMONTHDAY=-1", "0 0 0 L * ?", ""},
+		{"Days before month end", "FREQ=MONTHLY;BYMONTHDAY=1,-3", "0 0 0 1,L-2 * ?", ""},
+		{"Yearly", "FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25;BYHOUR=7", "0 0 7 25 12 ?", ""},
+		{"Hourly", "FREQ=HOURLY;BYMINUTE=0,30", "0 0,30 * * * ?", ""},
+		{"Interval", "FREQ=MINUTELY;INTERVAL=15", "@every 15m", ""},
+		{"Daily interval", "FREQ=DAILY;INTERVAL=2", "@every 48h", ""},
+		{"Count", "FREQ=DAILY;COUNT=10", "", CodeUnsupported},
+		{"Interval with BY parts", "FREQ=DAILY;INTERVAL=2;BYHOUR=9", "", CodeUnsupported},
+		{"Day from DTSTART", "FREQ=WEEKLY;BYHOUR=9", "", CodeUnsupported},
+		{"Both day parts", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "", CodeUnsupported},
+		{"Last Friday", "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9", "0 0 9 ? * FRIL", ""},
+		{"Negative ordinal", "FREQ=MONTHLY;BYDAY=-2FR", "", CodeUnsupported},
+		{"Ordinal in a daily rule", "FREQ=DAILY;BYDAY=1MO", "", CodeUnsupported},
+		{"Unknown FREQ", "FREQ=SOMETIMES", "", CodeInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseRRule(tt.rule)
+			if tt.wantCode != "" {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+					t.Fatalf("ParseRRule() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRRule() error = %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("ParseRRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleRoundTrip(t *testing.T) {
+	for _, expression := range []string{"0 9 * * 1-5", "0 0 * * MON#2", "0 0 * * 5L", "30 18 1,15 * *"} {
+		t.Run(expression, func(t *testing.T) {
+			expr, _ := ParseCron(expression)
+			rules, err := expr.RRules()
+			if err != nil || len(rules) != 1 {
+				t.Fatalf("RRules() = %v, %v", rules, err)
+			}
+			back, err := ParseRRule(rules[0])
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", rules[0], err)
+			}
+			if ok, counterexample := Equivalent(expr, back); !ok {
+				t.Errorf("ParseRRule(%q) = %q, differs at %s", rules[0], back, counterexample)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package cron_internal
+
+import "time"
+
+// searchYears bounds how far ahead Next looks before concluding that an
+// expression never fires. It comfortably covers a February 29th schedule
+// across the skipped leap year in 2100.
+const searchYears = 10
+
+// Next returns the first time strictly after t at which the expression
+// fires, in the expression's Location or, without one, in t's location.
+// It returns the zero time if there is no such time within the search
+// limit, e.g. for "0 0 30 2 *", or for @reboot. An @every expression fires
+// one interval after t.
+func (c *Expression) Next(t time.Time) time.Time {
+	if c.IsReboot() {
+		return time.Time{}
+	}
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+	if c.IsInterval() {
+		return t.Truncate(time.Second).Add(c.Interval)
+	}
+
+	seconds := c.secondValues()
+	minutes := c.Minute.Values()
+	hours := c.Hour.Values()
+	months := c.Month.Values()
+	years := c.Year.Values()
+
+	loc := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.Year() + searchYears
+	if c.Year.IsSet() {
+		limit = yearSpec.max
+	}
+
+	var days ValueSet
+	var daysFor time.Time
+
+	for t.Year() <= limit {
+		if c.Year.IsSet() && !years.Contains(t.Year()) {
+			t = startOf(loc, t.Year()+1, time.January, 1, 0)
+			continue
+		}
+		if !months.Contains(int(t.Month())) {
+			t = startOf(loc, t.Year(), t.Month()+1, 1, 0)
+			continue
+		}
+		if month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc); !month.Equal(daysFor) {
+			days = c.DaysIn(t.Year(), t.Month())
+			daysFor = month
+		}
+		if !days.Contains(t.Day()) {
+			t = startOf(loc, t.Year(), t.Month(), t.Day()+1, 0)
+			continue
+		}
+		if !hours.Contains(t.Hour()) {
+			t = startOf(loc, t.Year(), t.Month(), t.Day(), t.Hour()+1)
+			continue
+		}
+		if !minutes.Contains(t.Minute()) {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if !seconds.Contains(t.Second()) {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Prev returns the last time strictly before t at which the expression
+// fires, in the expression's Location or, without one, in t's location.
+// It returns the zero time if there is no such time within the search
+// limit, or for @reboot. An @every expression fired one interval before t.
+func (c *Expression) Prev(t time.Time) time.Time {
+	if c.IsReboot() {
+		return time.Time{}
+	}
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+	t = t.Add(-1).Truncate(time.Second)
+	if c.IsInterval() {
+		return t.Add(time.Second - c.Interval)
+	}
+
+	seconds := c.secondValues()
+	minutes := c.Minute.Values()
+	hours := c.Hour.Values()
+	months := c.Month.Values()
+	years := c.Year.Values()
+
+	loc := t.Location()
+	limit := t.Year() - searchYears
+	if c.Year.IsSet() {
+		limit = yearSpec.min
+	}
+
+	var days ValueSet
+	var daysFor time.Time
+
+	for t.Year() >= limit {
+		if c.Year.IsSet() && !years.Contains(t.Year()) {
+			t = startOf(loc, t.Year(), time.January, 1, 0).Add(-time.Second)
+			continue
+		}
+		if !months.Contains(int(t.Month())) {
+			t = startOf(loc, t.Year(), t.Month(), 1, 0).Add(-time.Second)
+			continue
+		}
+		if month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc); !month.Equal(daysFor) {
+			days = c.DaysIn(t.Year(), t.Month())
+			daysFor = month
+		}
+		if !days.Contains(t.Day()) {
+			t = startOf(loc, t.Year(), t.Month(), t.Day(), 0).Add(-time.Second)
+			continue
+		}
+		if !hours.Contains(t.Hour()) {
+			t = startOf(loc, t.Year(), t.Month(), t.Day(), t.Hour()).Add(-time.Second)
+			continue
+		}
+		if !minutes.Contains(t.Minute()) {
+			t = t.Truncate(time.Minute).Add(-time.Second)
+			continue
+		}
+		if !seconds.Contains(t.Second()) {
+			t = t.Add(-time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// startOf returns the first instant of the given hour in loc. Go resolves
+// a wall clock time skipped by a daylight saving transition to before the
+// transition, so an hour the clocks skip into starts at the transition.
+func startOf(loc *time.Location, year int, month time.Month, day, hour int) time.Time {
+	start := time.Date(year, month, day, hour, 0, 0, 0, loc)
+	if wallClock(start).Before(time.Date(year, month, day, hour, 0, 0, 0, time.UTC)) {
+		_, start = start.ZoneBounds()
+	}
+	return start
+}
+
+// secondValues returns the seconds at which the expression fires. Without a
+// seconds field that is only the start of each minute.
+func (c *Expression) secondValues() ValueSet {
+	if c.Second.IsSet() {
+		return c.Second.Values()
+	}
+	set := newValueSet(secondSpec.min, secondSpec.max)
+	set.Add(0)
+	return set
+}
+
+// DaysIn returns the days of the given month on which the expression may
+// fire, resolving L, W and # items against the calendar. When both day
+// fields are restricted a day matches if either field matches, as in
+// Vixie cron.
+func (c *Expression) DaysIn(year int, month time.Month) ValueSet {
+	last := daysInMonth(year, month)
+	domMatches := c.DayOfMonth.daysIn(year, month)
+	dowMatches := c.DayOfWeek.daysIn(year, month)
+
+	days := newValueSet(1, last)
+	for day := 1; day <= last; day++ {
+		var ok bool
+		switch {
+		case c.DayOfMonth.IsWildcard() && c.DayOfWeek.IsWildcard():
+			ok = true
+		case c.DayOfMonth.IsWildcard():
+			ok = dowMatches.Contains(day)
+		case c.DayOfWeek.IsWildcard():
+			ok = domMatches.Contains(day)
+		default:
+			ok = domMatches.Contains(day) || dowMatches.Contains(day)
+		}
+		if ok {
+			days.Add(day)
+		}
+	}
+	return days
+}
+
+// daysIn resolves a day of month or day of week field to the days of the
+// given month that it matches.
+func (f Field) daysIn(year int, month time.Month) ValueSet {
+	last := daysInMonth(year, month)
+	days := newValueSet(1, last)
+	values := f.Values()
+
+	for day := 1; day <= last; day++ {
+		var v int
+		if f.spec.name == fieldDayOfWeek {
+			v = int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday())
+		} else {
+			v = day
+		}
+		if values.Contains(v) {
+			days.Add(day)
+		}
+	}
+
+	walk(f.Root, func(n Node) bool {
+		if day := resolveDay(f.spec, n, year, month); day > 0 {
+			days.Add(day)
+		}
+		return false
+	})
+	return days
+}
+
+// resolveDay returns the day of the month selected by a calendar dependent
+// node, or 0 if the node does not select a day in that month.
+func resolveDay(spec fieldSpec, n Node, year int, month time.Month) int {
+	last := daysInMonth(year, month)
+	switch n := n.(type) {
+	case Last:
+		if spec.name == fieldDayOfMonth && n.Offset < last {
+			return last - n.Offset
+		}
+	case LastWeekday:
+		switch time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday() {
+		case time.Saturday:
+			return last - 1
+		case time.Sunday:
+			return last - 2
+		}
+		return last
+	case LastOccurrence:
+		weekday := time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday()
+		return last - (int(weekday)-spec.weekday(n.Weekday)+7)%7
+	case NearestWeekday:
+		return nearestWeekday(year, month, n.Day)
+	case NthWeekday:
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		day := 1 + (spec.weekday(n.Weekday)-int(first)+7)%7 + 7*(n.N-1)
+		if day <= last {
+			return day
+		}
+	}
+	return 0
+}
+
+// nearestWeekday returns the weekday closest to the given day without
+// leaving the month, or 0 if the month does not have that day.
+func nearestWeekday(year int, month time.Month, day int) int {
+	last := daysInMonth(year, month)
+	if day > last {
+		return 0
+	}
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Matches reports whether the expression fires at t, to the second, read
+// in the expression's Location if it has one. @every and @reboot
+// expressions match no fixed times.
+func (c *Expression) Matches(t time.Time) bool {
+	if c.IsInterval() || c.IsReboot() || t.Nanosecond() != 0 {
+		return false
+	}
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+	if c.Year.IsSet() && !c.Year.Values().Contains(t.Year()) {
+		return false
+	}
+	return c.Month.Values().Contains(int(t.Month())) &&
+		c.DaysIn(t.Year(), t.Month()).Contains(t.Day()) &&
+		c.Hour.Values().Contains(t.Hour()) &&
+		c.Minute.Values().Contains(t.Minute()) &&
+		c.secondValues().Contains(t.Second())
+}
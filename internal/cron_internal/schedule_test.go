@@ -0,0 +1,211 @@
+package cron_internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from       string
+		want       []string
+	}{
+		{"Every 15 minutes", "*/15 * * * *", "2024-03-10T10:07:00Z", []string{"2024-03-10T10:15:00Z", "2024-03-10T10:30:00Z"}},
+		{"Weekdays", "30 14 * * MON-FRI", "2024-03-08T15:00:00Z", []string{"2024-03-11T14:30:00Z", "2024-03-12T14:30:00Z"}},
+		{"Sunday as 7", "0 0 * * 7", "2024-03-08T00:00:00Z", []string{"2024-03-10T00:00:00Z", "2024-03-17T00:00:00Z"}},
+		{"Last day of month", "0 0 L * *", "2024-01-31T00:00:00Z", []string{"2024-02-29T00:00:00Z", "2024-03-31T00:00:00Z", "2024-04-30T00:00:00Z"}},
+		{"Nearest weekday on Saturday", "0 9 15W * *", "2024-06-01T00:00:00Z", []string{"2024-06-14T09:00:00Z", "2024-07-15T09:00:00Z"}},
+		{"Nearest weekday on the 1st", "0 9 1W * *", "2024-05-31T00:00:00Z", []string{"2024-06-03T09:00:00Z", "2024-07-01T09:00:00Z"}},
+		{"Nearest weekday at month end", "0 9 30W * *", "2024-06-01T00:00:00Z", []string{"2024-06-28T09:00:00Z", "2024-07-30T09:00:00Z"}},
+		{"Second Monday", "0 0 * * MON#2", "2024-01-01T00:00:00Z", []string{"2024-01-08T00:00:00Z", "2024-02-12T00:00:00Z"}},
+		{"Fifth Friday", "0 0 * * 5#5", "2024-01-01T00:00:00Z", []string{"2024-03-29T00:00:00Z", "2024-05-31T00:00:00Z"}},
+		{"Last weekday", "0 18 LW * *", "2024-08-01T00:00:00Z", []string{"2024-08-30T18:00:00Z", "2024-09-30T18:00:00Z", "2024-10-31T18:00:00Z", "2024-11-29T18:00:00Z"}},
+		{"Last weekday on a Sunday", "0 18 LW 8 *", "2025-01-01T00:00:00Z", []string{"2025-08-29T18:00:00Z"}},
+		{"Days before month end in a leap year", "0 0 L-3 * *", "2024-01-31T00:00:00Z", []string{"2024-02-26T00:00:00Z", "2024-03-28T00:00:00Z", "2024-04-27T00:00:00Z"}},
+		{"Days before month end in a common year", "0 0 L-3 2 *", "2023-01-01T00:00:00Z", []string{"2023-02-25T00:00:00Z", "2024-02-26T00:00:00Z"}},
+		{"Offset reaching the 1st only in leap years", "0 0 L-28 2 *", "2023-01-01T00:00:00Z", []string{"2024-02-01T00:00:00Z", "2028-02-01T00:00:00Z"}},
+		{"Last Friday", "0 0 * * 5L", "2024-01-01T00:00:00Z", []string{"2024-01-26T00:00:00Z", "2024-02-23T00:00:00Z", "2024-03-29T00:00:00Z"}},
+		{"Day fields are ORed", "0 0 13 * 5", "2024-09-01T00:00:00Z", []string{"2024-09-06T00:00:00Z", "2024-09-13T00:00:00Z", "2024-09-20T00:00:00Z"}},
+		{"Stepped range", "5-55/25 9 * * *", "2024-03-10T09:06:00Z", []string{"2024-03-10T09:30:00Z", "2024-03-10T09:55:00Z", "2024-03-11T09:05:00Z"}},
+		{"Stepped start", "0 3/12 * * *", "2024-03-10T04:00:00Z", []string{"2024-03-10T15:00:00Z", "2024-03-11T03:00:00Z"}},
+		{"Leap day", "0 0 29 2 *", "2024-03-01T00:00:00Z", []string{"2028-02-29T00:00:00Z"}},
+		{"Never fires", "0 0 30 2 *", "2024-01-01T00:00:00Z", []string{"0001-01-01T00:00:00Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			next, _ := time.Parse(time.RFC3339, tt.from)
+			for _, want := range tt.want {
+				next = expr.Next(next)
+				if got := next.Format(time.RFC3339); got != want {
+					t.Fatalf("Next() = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrev(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from       string
+		want       []string
+	}{
+		{"Every 15 minutes", "*/15 * * * *", "2024-03-10T10:07:00Z", []string{"2024-03-10T10:00:00Z", "2024-03-10T09:45:00Z"}},
+		{"Strictly before", "0 9 * * *", "2024-03-10T09:00:00Z", []string{"2024-03-09T09:00:00Z", "2024-03-08T09:00:00Z"}},
+		{"Weekdays", "30 14 * * MON-FRI", "2024-03-11T10:00:00Z", []string{"2024-03-08T14:30:00Z", "2024-03-07T14:30:00Z"}},
+		{"Last day of month", "0 0 L * *", "2024-03-15T00:00:00Z", []string{"2024-02-29T00:00:00Z", "2024-01-31T00:00:00Z", "2023-12-31T00:00:00Z"}},
+		{"Second Monday", "0 0 * * MON#2", "2024-03-01T00:00:00Z", []string{"2024-02-12T00:00:00Z", "2024-01-08T00:00:00Z"}},
+		{"Leap day", "0 0 29 2 *", "2028-01-01T00:00:00Z", []string{"2024-02-29T00:00:00Z", "2020-02-29T00:00:00Z"}},
+		{"Spring forward", "TZ=America/New_York 30 * * * *", "2024-03-10T03:10:00-04:00", []string{"2024-03-10T01:30:00-05:00", "2024-03-10T00:30:00-05:00"}},
+		{"Fall back", "TZ=America/New_York 30 1 * * *", "2024-11-03T03:00:00-05:00", []string{"2024-11-03T01:30:00-05:00", "2024-11-03T01:30:00-04:00", "2024-11-02T01:30:00-04:00"}},
+		{"Interval", "@every 90m", "2024-03-10T10:00:00Z", []string{"2024-03-10T08:30:00Z", "2024-03-10T07:00:00Z"}},
+		{"Never fires", "0 0 30 2 *", "2024-01-01T00:00:00Z", []string{"0001-01-01T00:00:00Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			prev, _ := time.Parse(time.RFC3339, tt.from)
+			for _, want := range tt.want {
+				prev = expr.Prev(prev)
+				if got := prev.Format(time.RFC3339); got != want {
+					t.Fatalf("Prev() = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrevReversesNext(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	for _, expression := range []string{"*/20 1-3 * * *", "0 0 * * 5L", "15 2 LW * *", "0 */5 * * SAT,SUN"} {
+		expr, _ := ParseCron(expression)
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, ny)
+		var runs []time.Time
+		for next := expr.Next(from); next.Year() == 2024; next = expr.Next(next) {
+			runs = append(runs, next)
+		}
+		prev := expr.Next(runs[len(runs)-1])
+		for i := len(runs) - 1; i >= 0; i-- {
+			if prev = expr.Prev(prev); !prev.Equal(runs[i]) {
+				t.Fatalf("%s: Prev() = %s, want %s", expression, prev, runs[i])
+			}
+		}
+	}
+}
+
+func TestNextQuartz(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from       string
+		want       []string
+	}{
+		{"Every 10 seconds", "*/10 * * * * ?", "2024-03-10T10:07:55Z", []string{"2024-03-10T10:08:00Z", "2024-03-10T10:08:10Z"}},
+		{"With seconds and weekday", "30 0 9 ? * MON-FRI", "2024-03-08T09:00:30Z", []string{"2024-03-11T09:00:30Z"}},
+		{"With year", "0 0 12 1 1 ? 2030", "2024-01-01T00:00:00Z", []string{"2030-01-01T12:00:00Z", "0001-01-01T00:00:00Z"}},
+		{"Every other year", "0 0 12 1 1 ? */2", "2024-06-01T00:00:00Z", []string{"2026-01-01T12:00:00Z", "2028-01-01T12:00:00Z"}},
+		{"Year step from a start", "0 0 12 1 1 ? 2024/2", "2024-06-01T00:00:00Z", []string{"2026-01-01T12:00:00Z", "2028-01-01T12:00:00Z"}},
+		{"Year range step", "0 0 12 1 1 ? 2024-2030/3", "2024-06-01T00:00:00Z", []string{"2027-01-01T12:00:00Z", "2030-01-01T12:00:00Z", "0001-01-01T00:00:00Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronWithMode(tt.expression, ModeQuartz)
+			if err != nil {
+				t.Fatalf("ParseCronWithMode() error = %v", err)
+			}
+			next, _ := time.Parse(time.RFC3339, tt.from)
+			for _, want := range tt.want {
+				next = expr.Next(next)
+				if got := next.Format(time.RFC3339); got != want {
+					t.Fatalf("Next() = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextMacros(t *testing.T) {
+	from := time.Date(2024, 3, 10, 10, 7, 30, 0, time.UTC)
+
+	every, _ := ParseCron("@every 1h30m")
+	if got, want := every.Next(from), from.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("@every Next() = %v, want %v", got, want)
+	}
+
+	reboot, _ := ParseCron("@reboot")
+	if got := reboot.Next(from); !got.IsZero() {
+		t.Errorf("@reboot Next() = %v, want zero time", got)
+	}
+
+	daily, _ := ParseCron("@daily")
+	if got, want := daily.Next(from), time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("@daily Next() = %v, want %v", got, want)
+	}
+}
+
+func TestNextTimeZone(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from       string
+		want       string
+		wantUTC    string
+	}{
+		{"Kolkata", "CRON_TZ=Asia/Kolkata 0 9 * * *", "2024-03-10T00:00:00Z", "2024-03-10T09:00:00+05:30", "2024-03-10T03:30:00Z"},
+		{"TZ prefix", "TZ=America/New_York 0 9 * * *", "2024-03-10T00:00:00Z", "2024-03-10T09:00:00-04:00", "2024-03-10T13:00:00Z"},
+		{"Macro", "CRON_TZ=Europe/Berlin @daily", "2024-07-01T12:00:00Z", "2024-07-02T00:00:00+02:00", "2024-07-01T22:00:00Z"},
+		{"Across a skipped hour", "TZ=America/New_York 30 * * * *", "2024-03-10T06:40:00Z", "2024-03-10T03:30:00-04:00", "2024-03-10T07:30:00Z"},
+		{"Local time of the input is ignored", "CRON_TZ=UTC 30 6 * * *", "2024-03-10T05:00:00-02:00", "2024-03-11T06:30:00Z", "2024-03-11T06:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			from, _ := time.Parse(time.RFC3339, tt.from)
+			next := expr.Next(from)
+			if got := next.Format(time.RFC3339); got != tt.want {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+			if got := next.UTC().Format(time.RFC3339); got != tt.wantUTC {
+				t.Errorf("Next() in UTC = %s, want %s", got, tt.wantUTC)
+			}
+			if !expr.Matches(next) {
+				t.Errorf("Matches(%s) = false, want true", next)
+			}
+		})
+	}
+}
+
+func TestParseTimeZone(t *testing.T) {
+	expr, err := ParseCron("TZ=Asia/Kolkata 0 9 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	if got, want := expr.String(), "CRON_TZ=Asia/Kolkata 0 9 * * *"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, expression := range []string{"CRON_TZ=Mars/Olympus 0 9 * * *", "TZ= 0 9 * * *"} {
+		var cronErr *ValidationError
+		if _, err := ParseCron(expression); !errors.As(err, &cronErr) || cronErr.Code != CodeInvalidTimeZone {
+			t.Errorf("ParseCron(%q) error = %v, want code %s", expression, err, CodeInvalidTimeZone)
+		}
+	}
+}
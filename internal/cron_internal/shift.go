@@ -0,0 +1,44 @@
+package cron_internal
+
+import "fmt"
+
+// MinuteSlack returns how many minutes later the expression's runs can move
+// without leaving their hour: 59 less the last minute it fires at. It is 0
+// for @every and @reboot, and for a minute field using H, whose values are
+// not fixed until resolved.
+func (c *Expression) MinuteSlack() int {
+	if c.IsInterval() || c.IsReboot() || walk(c.Minute.Root, func(n Node) bool { _, ok := n.(Hash); return ok }) {
+		return 0
+	}
+	values := c.Minute.Values().Values()
+	return minuteSpec.max - values[len(values)-1]
+}
+
+// ShiftMinutes returns the expression with every run moved n minutes later
+// within its hour, e.g. "0 */2 * * *" moved 7 minutes is "7 */2 * * *".
+// The other fields are kept, so the schedule runs as often and on the same
+// hours and days. A calendar macro is replaced by its fields. n must be
+// between 0 and MinuteSlack.
+func (c *Expression) ShiftMinutes(n int) (*Expression, error) {
+	if n < 0 || n > c.MinuteSlack() {
+		return nil, fmt.Errorf("cannot move %s %d minutes later within the hour", c, n)
+	}
+	if n == 0 {
+		return c, nil
+	}
+	values := c.Minute.Values()
+	shifted := newValueSet(values.Min, values.Max)
+	for _, v := range values.Values() {
+		shifted.Add(v + n)
+	}
+	items := compressValues(shifted, c.Minute.spec, NormalizeOptions{})
+	var root Node = List{Items: items}
+	if len(items) == 1 {
+		root = items[0]
+	}
+
+	s := *c
+	s.Macro = ""
+	s.Minute = Field{Raw: root.String(), Root: root, spec: c.Minute.spec}
+	return &s, nil
+}
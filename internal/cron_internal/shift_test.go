@@ -0,0 +1,63 @@
+package cron_internal
+
+import "testing"
+
+func TestShiftMinutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		dialect    *Dialect
+		minutes    int
+		wantSlack  int
+		want       string
+	}{
+		{"Top of the hour", "0 */2 * * *", Standard, 7, 59, "7 */2 * * *"},
+		{"Step", "*/15 9-17 * * 1-5", Standard, 7, 14, "7-52/15 9-17 * * 1-5"},
+		{"List", "0,5,30 * * * *", Standard, 20, 29, "20,25,50 * * * *"},
+		{"Macro", "@daily", Standard, 30, 59, "30 0 * * *"},
+		{"Time zone kept", "CRON_TZ=UTC 0 9 * * *", Standard, 5, 59, "CRON_TZ=UTC 5 9 * * *"},
+		{"Seconds kept", "30 0 12 * * ?", Quartz, 1, 59, "30 1 12 * * ?"},
+		{"No shift", "0 9 * * *", Standard, 0, 59, "0 9 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronDialect(tt.expression, tt.dialect)
+			if err != nil {
+				t.Fatalf("ParseCronDialect() error = %v", err)
+			}
+			if got := expr.MinuteSlack(); got != tt.wantSlack {
+				t.Errorf("MinuteSlack() = %d, want %d", got, tt.wantSlack)
+			}
+			shifted, err := expr.ShiftMinutes(tt.minutes)
+			if err != nil {
+				t.Fatalf("ShiftMinutes() error = %v", err)
+			}
+			if got := shifted.String(); got != tt.want {
+				t.Errorf("ShiftMinutes() = %q, want %q", got, tt.want)
+			}
+			if err := ValidateCronDialect(shifted.String(), tt.dialect); err != nil {
+				t.Errorf("shifted expression %q is invalid: %v", shifted, err)
+			}
+		})
+	}
+}
+
+func TestShiftMinutesOutOfSlack(t *testing.T) {
+	for _, tt := range []struct {
+		expression string
+		dialect    *Dialect
+		minutes    int
+	}{
+		{"*/15 * * * *", Standard, 15},
+		{"* 9 * * *", Standard, 1},
+		{"@every 1h", Standard, 1},
+		{"H * * * *", Jenkins, 1},
+		{"0 9 * * *", Standard, -1},
+	} {
+		expr, _ := ParseCronDialect(tt.expression, tt.dialect)
+		if _, err := expr.ShiftMinutes(tt.minutes); err == nil {
+			t.Errorf("ShiftMinutes(%d) of %q succeeded", tt.minutes, tt.expression)
+		}
+	}
+}
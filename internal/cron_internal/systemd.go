@@ -0,0 +1,309 @@
+package cron_internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// onCalendarShorthands maps the systemd calendar shorthands to their full
+// calendar event form.
+var onCalendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// systemdWeekdays are the weekday names systemd prints, indexed from Sunday.
+var systemdWeekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// ParseOnCalendar parses a systemd OnCalendar= calendar event, e.g.
+// "Mon..Fri *-*-* 09:00:00" or "*-*-01 00:00:00", into an expression in
+// the Quartz layout, so that it can be described, previewed with Next or
+// transpiled to another dialect.
+//
+// systemd requires both the weekday and the date to match, whereas cron
+// fires when either day field matches, so events restricting both are
+// rejected with CodeUnsupported. Time zones are not supported.
+func ParseOnCalendar(event string) (*Expression, error) {
+	event = strings.TrimSpace(event)
+	if full, ok := onCalendarShorthands[strings.ToLower(event)]; ok {
+		event = full
+	}
+
+	parts := strings.Fields(event)
+	weekdays, date, clock := "*", "*-*-*", "00:00:00"
+	if len(parts) > 0 && !strings.ContainsAny(parts[0], "-:~") && parts[0] != "*" {
+		weekdays, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 0 && strings.ContainsAny(parts[0], "-~") {
+		date, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 0 && strings.Contains(parts[0], ":") {
+		clock, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 0 {
+		if isTimeZoneName(parts[0]) {
+			return nil, &ValidationError{"expression", fmt.Sprintf("unexpected %q in calendar event; time zones are not supported", parts[0]), CodeUnsupported}
+		}
+		return nil, &ValidationError{"expression", fmt.Sprintf("unexpected %q in calendar event; expected a weekday, a date such as *-*-01 and a time such as 09:00", parts[0]), CodeInvalidValue}
+	}
+
+	dayOfWeek, err := onCalendarWeekdays(weekdays)
+	if err != nil {
+		return nil, err
+	}
+	year, month, day, err := splitOnCalendarDate(date)
+	if err != nil {
+		return nil, err
+	}
+	times := strings.Split(clock, ":")
+	if len(times) == 2 {
+		times = append(times, "00")
+	}
+	if len(times) != 3 {
+		return nil, &ValidationError{"expression", "time must be written as hh:mm or hh:mm:ss", CodeFieldCount}
+	}
+
+	switch {
+	case dayOfWeek == "*":
+		dayOfWeek = "?"
+	case day == "*":
+		day = "?"
+	default:
+		return nil, &ValidationError{fieldDayOfWeek, "systemd matches both the weekday and the date, which cron cannot express", CodeUnsupported}
+	}
+
+	fields := []string{onCalendarValue(times[2]), onCalendarValue(times[1]), onCalendarValue(times[0]), day, onCalendarValue(month), dayOfWeek}
+	if year != "*" {
+		fields = append(fields, onCalendarValue(year))
+	}
+	expr, err := ParseCronWithMode(strings.Join(fields, " "), ModeQuartz)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// isTimeZoneName reports whether a calendar event token names a time zone,
+// such as "UTC" or "Europe/Berlin".
+func isTimeZoneName(token string) bool {
+	if strings.Contains(token, "/") {
+		return true
+	}
+	_, err := time.LoadLocation(token)
+	return err == nil
+}
+
+// splitOnCalendarDate splits a date such as "*-*-01", "01-01" or "*-02~01"
+// into its year, month and day of month, rewriting "~01" as L and "~0n" as
+// L-(n-1).
+func splitOnCalendarDate(date string) (year, month, day string, err error) {
+	separator := "-"
+	if i := strings.LastIndex(date, "~"); i >= 0 {
+		separator = "~"
+		date = date[:i] + "-" + date[i+1:]
+	}
+	parts := strings.Split(date, "-")
+	if len(parts) == 2 {
+		parts = append([]string{"*"}, parts...)
+	}
+	if len(parts) != 3 {
+		return "", "", "", &ValidationError{"expression", "date must be written as yyyy-mm-dd or mm-dd", CodeFieldCount}
+	}
+	year, month, day = parts[0], parts[1], onCalendarValue(parts[2])
+	if separator == "~" {
+		v, err := strconv.Atoi(parts[2])
+		if err != nil || v < 1 || v > 31 {
+			return "", "", "", &ValidationError{fieldDayOfMonth, "only a single day counted from the end of the month is supported after ~", CodeUnsupported}
+		}
+		day = Last{v - 1}.String()
+	}
+	return year, month, day, nil
+}
+
+// onCalendarValue rewrites a systemd field in cron syntax, where ranges use
+// "-" instead of "..".
+func onCalendarValue(value string) string {
+	return strings.ReplaceAll(value, "..", "-")
+}
+
+// onCalendarWeekdays rewrites systemd weekdays such as "Mon..Fri,Sun" or
+// "Monday" in cron syntax.
+func onCalendarWeekdays(weekdays string) (string, error) {
+	if weekdays == "*" {
+		return "*", nil
+	}
+	items := strings.Split(weekdays, ",")
+	for i, item := range items {
+		days := strings.Split(item, "..")
+		if len(days) > 2 {
+			return "", &ValidationError{fieldDayOfWeek, "invalid range", CodeInvalidRange}
+		}
+		for j, day := range days {
+			index := -1
+			for k, name := range systemdWeekdays {
+				if strings.EqualFold(day, name) || strings.EqualFold(day, time.Weekday(k).String()) {
+					index = k
+				}
+			}
+			if index < 0 {
+				return "", &ValidationError{fieldDayOfWeek, fmt.Sprintf("%q is not a weekday", day), CodeInvalidValue}
+			}
+			days[j] = weekdayNames[index]
+		}
+		items[i] = strings.Join(days, "-")
+	}
+	return strings.Join(items, ","), nil
+}
+
+// OnCalendar returns the systemd OnCalendar= calendar event matching the
+// expression, e.g. "Mon..Fri *-*-* 09:00:00". Expressions that restrict
+// both day fields, use W, #, LW or dL, or are @every or @reboot have no
+// calendar event and are rejected with CodeUnsupported.
+func (c *Expression) OnCalendar() (string, error) {
+	if c.IsReboot() || c.IsInterval() {
+		return "", &ValidationError{"expression", c.Macro + " has no calendar event", CodeUnsupported}
+	}
+	if !c.DayOfMonth.IsWildcard() && !c.DayOfWeek.IsWildcard() {
+		return "", &ValidationError{fieldDayOfWeek, "cron fires when either day field matches, which systemd cannot express", CodeUnsupported}
+	}
+	for _, f := range []Field{c.DayOfMonth, c.DayOfWeek} {
+		if f.IsCalendarDependent() && !isLast(f.Root) {
+			return "", &ValidationError{f.Name(), "'" + f.String() + "' is not supported by systemd", CodeUnsupported}
+		}
+	}
+
+	var parts []string
+	if !c.DayOfWeek.IsWildcard() {
+		parts = append(parts, formatOnCalendar(c.DayOfWeek, 0))
+	}
+	year := "*"
+	if c.Year.IsSet() {
+		year = formatOnCalendar(c.Year, 4)
+	}
+	date := year + "-" + formatOnCalendar(c.Month, 2) + "-" + formatOnCalendar(c.DayOfMonth, 2)
+	if last, ok := c.DayOfMonth.Root.(Last); ok {
+		date = fmt.Sprintf("%s-%s~%02d", year, formatOnCalendar(c.Month, 2), last.Offset+1)
+	}
+	second := "00"
+	if c.Second.IsSet() {
+		second = formatOnCalendar(c.Second, 2)
+	}
+	parts = append(parts, date, formatOnCalendar(c.Hour, 2)+":"+formatOnCalendar(c.Minute, 2)+":"+second)
+	return strings.Join(parts, " "), nil
+}
+
+func isLast(n Node) bool {
+	_, ok := n.(Last)
+	return ok
+}
+
+// formatOnCalendar writes a field in systemd syntax with numbers padded to
+// width digits. Weekdays are written as names when width is 0.
+func formatOnCalendar(f Field, width int) string {
+	if f.IsWildcard() {
+		return "*"
+	}
+	format := func(v int) string {
+		if width == 0 {
+			return systemdWeekdays[v]
+		}
+		return fmt.Sprintf("%0*d", width, v)
+	}
+	if step, ok := f.Root.(Step); ok && width > 0 {
+		switch base := step.Base.(type) {
+		case Wildcard:
+			return format(f.spec.min) + "/" + strconv.Itoa(step.Interval)
+		case Value:
+			return format(base.Value) + "/" + strconv.Itoa(step.Interval)
+		}
+	}
+
+	values := f.Values().Values()
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, format(values[i])+".."+format(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, format(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// SystemdUnitOptions configures the unit pair written by SystemdUnits.
+type SystemdUnitOptions struct {
+	// Name is the unit name without suffix, e.g. "backup" for backup.timer
+	// and backup.service.
+	Name        string
+	Description string
+	// Command is the service's ExecStart= command line.
+	Command string
+	// User runs the command as another user when set.
+	User string
+}
+
+// SystemdUnits is a .timer unit and the .service unit it activates.
+type SystemdUnits struct {
+	TimerName   string `json:"timer_name"`
+	Timer       string `json:"timer"`
+	ServiceName string `json:"service_name"`
+	Service     string `json:"service"`
+}
+
+// SystemdUnits generates a .timer/.service unit pair that runs a command
+// on the expression's schedule. Calendar schedules use OnCalendar= with
+// Persistent=true, so runs missed while the host was down happen at boot;
+// @reboot uses OnBootSec= and @every uses OnUnitActiveSec=.
+func (c *Expression) SystemdUnits(opts SystemdUnitOptions) (SystemdUnits, error) {
+	if opts.Name == "" || opts.Command == "" {
+		return SystemdUnits{}, fmt.Errorf("systemd units need a name and a command")
+	}
+	description := opts.Description
+	if description == "" {
+		description = c.Describe()
+	}
+
+	var timer []string
+	switch {
+	case c.IsReboot():
+		timer = []string{"OnBootSec=0"}
+	case c.IsInterval():
+		interval := strings.TrimPrefix(c.Macro, macroEvery+" ")
+		timer = []string{"OnBootSec=" + interval, "OnUnitActiveSec=" + interval}
+	default:
+		event, err := c.OnCalendar()
+		if err != nil {
+			return SystemdUnits{}, err
+		}
+		timer = []string{"OnCalendar=" + event, "Persistent=true"}
+	}
+
+	units := SystemdUnits{TimerName: opts.Name + ".timer", ServiceName: opts.Name + ".service"}
+	units.Timer = fmt.Sprintf("[Unit]\nDescription=%s\n\n[Timer]\n%s\nUnit=%s\n\n[Install]\nWantedBy=timers.target\n",
+		description, strings.Join(timer, "\n"), units.ServiceName)
+	service := []string{"Type=oneshot", "ExecStart=" + opts.Command}
+	if opts.User != "" {
+		service = append(service, "User="+opts.User)
+	}
+	units.Service = fmt.Sprintf("[Unit]\nDescription=%s\n\n[Service]\n%s\n", description, strings.Join(service, "\n"))
+	return units, nil
+}
@@ -0,0 +1,134 @@
+package cron_internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOnCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		want     string
+		next     string
+		wantCode string
+	}{
+		{"Weekdays", "Mon..Fri *-*-* 09:00:00", "0 0 9 ? * MON-FRI", "2024-03-11T09:00:00Z", ""},
+		{"First of the month", "*-*-01 00:00:00", "0 0 0 1 * ?", "2024-04-01T00:00:00Z", ""},
+		{"Full weekday names", "Monday,Wednesday 12:30", "0 30 12 ? * MON,WED", "2024-03-11T12:30:00Z", ""},
+		{"Repetition", "*-*-* *:00/15:00", "0 0/15 * * * ?", "2024-03-09T00:15:00Z", ""},
+		{"Month and day only", "01-01 06:00", "0 0 6 1 1 ?", "2025-01-01T06:00:00Z", ""},
+		{"Year", "2030-06-15 08:00:00", "0 0 8 15 6 ? 2030", "2030-06-15T08:00:00Z", ""},
+		{"Last day of month", "*-*~01 23:00", "0 0 23 L * ?", "2024-03-31T23:00:00Z", ""},
+		{"Third to last day", "*-02~03 12:00", "0 0 12 L-2 2 ?", "2025-02-26T12:00:00Z", ""},
+		{"Shorthand", "weekly", "0 0 0 ? * MON", "2024-03-11T00:00:00Z", ""},
+		{"Quarterly", "quarterly", "0 0 0 1 1,4,7,10 ?", "2024-04-01T00:00:00Z", ""},
+		{"Weekday and date", "Fri *-*-13 00:00:00", "", "", CodeUnsupported},
+		{"Time zone", "*-*-* 09:00:00 Europe/Berlin", "", "", CodeUnsupported},
+		{"UTC", "Mon 09:00 UTC", "", "", CodeUnsupported},
+		{"Malformed time", "Wed 18", "", "", CodeInvalidValue},
+		{"Bad weekday", "Funday *-*-* 09:00", "", "", CodeInvalidValue},
+	}
+
+	from := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseOnCalendar(tt.event)
+			if tt.wantCode != "" {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+					t.Fatalf("ParseOnCalendar() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOnCalendar() error = %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("ParseOnCalendar() = %q, want %q", got, tt.want)
+			}
+			if got := expr.Next(from).Format(time.RFC3339); got != tt.next {
+				t.Errorf("Next() = %s, want %s", got, tt.next)
+			}
+		})
+	}
+}
+
+func TestOnCalendar(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		mode       ParseMode
+		want       string
+		wantCode   string
+	}{
+		{"Weekdays", "0 9 * * 1-5", ModeStandard, "Mon..Fri *-*-* 09:00:00", ""},
+		{"Sunday as 7", "0 9 * * 6,7", ModeStandard, "Sun,Sat *-*-* 09:00:00", ""},
+		{"Every 15 minutes", "*/15 * * * *", ModeStandard, "*-*-* *:00/15:00", ""},
+		{"Monthly", "@monthly", ModeStandard, "*-*-01 00:00:00", ""},
+		{"Last day", "30 18 L * *", ModeStandard, "*-*~01 18:30:00", ""},
+		{"Days before month end", "30 18 L-2 * *", ModeStandard, "*-*~03 18:30:00", ""},
+		{"Last weekday", "0 0 LW * *", ModeStandard, "", CodeUnsupported},
+		{"Last Friday", "0 0 * * 5L", ModeStandard, "", CodeUnsupported},
+		{"Seconds and year", "15 0 12 1 1 ? 2030", ModeQuartz, "2030-01-01 12:00:15", ""},
+		{"Both day fields", "0 0 13 * 5", ModeStandard, "", CodeUnsupported},
+		{"Nth weekday", "0 0 * * 1#2", ModeStandard, "", CodeUnsupported},
+		{"Interval", "@every 1h", ModeStandard, "", CodeUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronWithMode(tt.expression, tt.mode)
+			if err != nil {
+				t.Fatalf("ParseCronWithMode() error = %v", err)
+			}
+			got, err := expr.OnCalendar()
+			if tt.wantCode != "" {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+					t.Fatalf("OnCalendar() = %q, %v, want code %s", got, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OnCalendar() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OnCalendar() = %q, want %q", got, tt.want)
+			}
+			if _, err := ParseOnCalendar(got); err != nil {
+				t.Errorf("ParseOnCalendar(%q) error = %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	expr, _ := ParseCron("0 9 * * 1-5")
+	units, err := expr.SystemdUnits(SystemdUnitOptions{Name: "report", Command: "/usr/local/bin/report", User: "reports"})
+	if err != nil {
+		t.Fatalf("SystemdUnits() error = %v", err)
+	}
+	for _, want := range []string{"Description=At 09:00, Monday through Friday", "OnCalendar=Mon..Fri *-*-* 09:00:00", "Persistent=true", "Unit=report.service", "WantedBy=timers.target"} {
+		if !strings.Contains(units.Timer, want) {
+			t.Errorf("Timer missing %q:\n%s", want, units.Timer)
+		}
+	}
+	for _, want := range []string{"Type=oneshot", "ExecStart=/usr/local/bin/report", "User=reports"} {
+		if !strings.Contains(units.Service, want) {
+			t.Errorf("Service missing %q:\n%s", want, units.Service)
+		}
+	}
+
+	every, _ := ParseCron("@every 90m")
+	units, err = every.SystemdUnits(SystemdUnitOptions{Name: "sync", Command: "sync"})
+	if err != nil || !strings.Contains(units.Timer, "OnUnitActiveSec=90m") {
+		t.Errorf("SystemdUnits() = %q, %v, want OnUnitActiveSec=90m", units.Timer, err)
+	}
+
+	if _, err := expr.SystemdUnits(SystemdUnitOptions{Name: "report"}); err == nil {
+		t.Errorf("SystemdUnits() without a command error = nil, want error")
+	}
+}
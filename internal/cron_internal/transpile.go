@@ -0,0 +1,305 @@
+package cron_internal
+
+import (
+	"strings"
+	"time"
+)
+
+// Transpile rewrites an expression for the target dialect. It shifts
+// weekday numbering, adds or drops the seconds and year fields and swaps
+// '?' for '*' where the target does not need it. A time zone prefix is
+// kept.
+//
+// Usually a single expression is returned. When the target cannot say the
+// same thing in one expression, Transpile returns several expressions that
+// together fire at exactly the original times: L in day of month becomes
+// one expression per month length, and both day fields restricted becomes
+// one expression per day field in dialects that require '?'. Schedules the
+// target cannot express at all, such as W, or L in February, are reported
+// with CodeUnsupported. A macro is kept only when the target accepts it
+// and gives it the same meaning; Jenkins hashes the calendar macros, so
+// between Jenkins and other dialects they are expanded to fields.
+func Transpile(c *Expression, target *Dialect) ([]*Expression, error) {
+	sameMeaning := c.dialect().HashedMacros == target.HashedMacros || c.IsReboot() || c.IsInterval()
+	if c.Macro != "" && sameMeaning && target.validateMacro(c.Macro) == nil {
+		return parseTranspiled(c.timeZonePrefix()+c.Macro, target)
+	}
+	if c.IsReboot() || c.IsInterval() {
+		return nil, target.validateMacro(c.Macro)
+	}
+
+	second, err := transpileSeconds(c, target)
+	if err != nil {
+		return nil, err
+	}
+	year, err := transpileYear(c, target)
+	if err != nil {
+		return nil, err
+	}
+	dayOfWeek, err := transpileWeekdays(c.DayOfWeek, target)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := dayTerms(c, target, dayOfWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	var expressions []*Expression
+	for _, term := range terms {
+		fields := []string{c.Minute.String(), c.Hour.String(), term.dayOfMonth, term.month, term.dayOfWeek}
+		if second != "" {
+			fields = append([]string{second}, fields...)
+		}
+		if year != "" {
+			fields = append(fields, year)
+		}
+		expr, err := parseTranspiled(c.timeZonePrefix()+strings.Join(fields, " "), target)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr...)
+	}
+	return expressions, nil
+}
+
+func parseTranspiled(expression string, target *Dialect) ([]*Expression, error) {
+	expr, err := ParseCronDialect(expression, target)
+	if err == nil {
+		err = expr.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*Expression{expr}, nil
+}
+
+// transpileSeconds returns the seconds field for the target, or "" if the
+// target has none. Dropping seconds is only exact when they are always 0.
+func transpileSeconds(c *Expression, target *Dialect) (string, error) {
+	if target.Mode == ModeQuartz {
+		if c.Second.IsSet() {
+			return c.Second.String(), nil
+		}
+		return "0", nil
+	}
+	if c.Second.IsSet() {
+		if values := c.Second.Values(); values.Len() != 1 || !values.Contains(0) {
+			return "", &ValidationError{fieldSecond, "seconds other than 0 are not supported by " + target.Title, CodeUnsupported}
+		}
+	}
+	return "", nil
+}
+
+// transpileYear returns the year field for the target, or "" if the target
+// has none or does not need one.
+func transpileYear(c *Expression, target *Dialect) (string, error) {
+	restricted := c.Year.IsSet() && !c.Year.IsWildcard()
+	switch {
+	case restricted && target.Mode == ModeStandard:
+		return "", &ValidationError{fieldYear, "a year field is not supported by " + target.Title, CodeUnsupported}
+	case target.Mode == ModeStandard:
+		return "", nil
+	case target.Mode == ModeEventBridge && !restricted:
+		return "*", nil
+	case c.Year.IsSet():
+		return c.Year.String(), nil
+	}
+	return "", nil
+}
+
+// transpileWeekdays rewrites a day of week field in the target's weekday
+// numbering, returning "" for an unrestricted field. Names mean the same
+// day in every dialect and are kept as written.
+func transpileWeekdays(f Field, target *Dialect) (string, error) {
+	if f.IsWildcard() {
+		return "", nil
+	}
+	spec := target.dayOfWeekSpec()
+	convert := func(v Value) Value {
+		if v.Name != "" {
+			return v
+		}
+		return Value{Value: f.spec.weekday(v.Value) + spec.min}
+	}
+	// fallback writes an item as the weekdays it matches, for ranges that
+	// would wrap around in the target's numbering.
+	fallback := func(n Node) Node {
+		set := newValueSet(f.spec.min, f.spec.max)
+		n.expand(f.spec, &set)
+		folded := newValueSet(0, 6)
+		for _, v := range set.Values() {
+			folded.Add(f.spec.weekday(v))
+		}
+		return List{Items: compressValues(folded, spec, NormalizeOptions{})}
+	}
+
+	var err error
+	var transpile func(n Node) Node
+	transpile = func(n Node) Node {
+		switch n := n.(type) {
+		case Value:
+			return convert(n)
+		case Range:
+			start, end := convert(n.Start), convert(n.End)
+			if start.Name == "" && end.Name == "" && end.Value < start.Value {
+				return fallback(n)
+			}
+			return Range{start, end}
+		case Step:
+			switch base := n.Base.(type) {
+			case Value:
+				return Step{convert(base), n.Interval}
+			case Range:
+				if r, ok := transpile(base).(Range); ok {
+					return Step{r, n.Interval}
+				}
+				return fallback(n)
+			}
+			return n
+		case List:
+			items := make([]Node, len(n.Items))
+			for i, item := range n.Items {
+				items[i] = transpile(item)
+			}
+			return List{Items: items}
+		case Last:
+			if !target.AllowLast {
+				return Value{Value: spec.saturday()}
+			}
+		case NthWeekday:
+			if !target.AllowNthWeekday {
+				err = &ValidationError{fieldDayOfWeek, "'" + n.String() + "' is not supported by " + target.Title, CodeUnsupported}
+			}
+			n.Weekday = f.spec.weekday(n.Weekday) + spec.min
+			return n
+		case LastOccurrence:
+			if !target.AllowLast {
+				err = &ValidationError{fieldDayOfWeek, "'" + n.String() + "' is not supported by " + target.Title, CodeUnsupported}
+			}
+			n.Weekday = f.spec.weekday(n.Weekday) + spec.min
+			return n
+		}
+		return n
+	}
+	root := transpile(f.Root)
+	if err != nil {
+		return "", err
+	}
+	return root.String(), nil
+}
+
+// dayTerm is one expression's worth of day and month fields.
+type dayTerm struct {
+	dayOfMonth, month, dayOfWeek string
+}
+
+// dayTerms splits the day fields into the expressions the target needs.
+// An empty dayOfWeek means the day of week field is unrestricted.
+func dayTerms(c *Expression, target *Dialect, dayOfWeek string) ([]dayTerm, error) {
+	month := c.Month.String()
+	var dayOfMonth []dayTerm
+	if !c.DayOfMonth.IsWildcard() {
+		var err error
+		if dayOfMonth, err = dayOfMonthTerms(c, target); err != nil {
+			return nil, err
+		}
+	}
+
+	every, none := "*", "*"
+	if target.RequireNoSpecific {
+		none = "?"
+	}
+
+	switch {
+	case len(dayOfMonth) == 0 && dayOfWeek == "":
+		return []dayTerm{{every, month, none}}, nil
+	case len(dayOfMonth) == 0:
+		return []dayTerm{{none, month, dayOfWeek}}, nil
+	case dayOfWeek == "":
+		for i := range dayOfMonth {
+			dayOfMonth[i].dayOfWeek = none
+		}
+		return dayOfMonth, nil
+	}
+
+	// Both day fields are restricted, so a day matches if either does.
+	if !target.RequireNoSpecific && len(dayOfMonth) == 1 && dayOfMonth[0].month == month {
+		dayOfMonth[0].dayOfWeek = dayOfWeek
+		return dayOfMonth, nil
+	}
+	for i := range dayOfMonth {
+		dayOfMonth[i].dayOfWeek = none
+	}
+	return append(dayOfMonth, dayTerm{none, month, dayOfWeek}), nil
+}
+
+// dayOfMonthTerms returns the day of month items, with L and L-n rewritten
+// as one term per month length when the target does not support them.
+func dayOfMonthTerms(c *Expression, target *Dialect) ([]dayTerm, error) {
+	month := c.Month.String()
+	f := c.DayOfMonth
+	var unsupported error
+	walk(f.Root, func(n Node) bool {
+		switch n.(type) {
+		case List, Step, Last:
+			return false
+		}
+		unsupported = target.checkNode(f.spec, n)
+		return unsupported != nil
+	})
+	if unsupported != nil {
+		return nil, unsupported
+	}
+	if target.AllowLast || !walk(f.Root, func(n Node) bool { _, ok := n.(Last); return ok }) {
+		return []dayTerm{{f.String(), month, ""}}, nil
+	}
+
+	var terms []dayTerm
+	var plain []Node
+	var lasts []Last
+	items := []Node{f.Root}
+	if list, ok := f.Root.(List); ok {
+		items = list.Items
+	}
+	for _, item := range items {
+		if last, ok := item.(Last); ok {
+			lasts = append(lasts, last)
+		} else {
+			plain = append(plain, item)
+		}
+	}
+	if len(plain) > 0 {
+		terms = append(terms, dayTerm{List{Items: plain}.String(), month, ""})
+	}
+
+	months := c.Month.Values()
+	if months.Contains(int(time.February)) {
+		return nil, &ValidationError{fieldDayOfMonth, "'L' in February depends on leap years and is not supported by " + target.Title, CodeUnsupported}
+	}
+	byLength := map[int]ValueSet{}
+	for _, m := range months.Values() {
+		days := daysInMonth(2001, time.Month(m))
+		set, ok := byLength[days]
+		if !ok {
+			set = newValueSet(monthSpec.min, monthSpec.max)
+		}
+		set.Add(m)
+		byLength[days] = set
+	}
+	for _, days := range []int{31, 30} {
+		if set, ok := byLength[days]; ok {
+			month := List{Items: compressValues(set, monthSpec, NormalizeOptions{})}.String()
+			lastDays := newValueSet(dayOfMonthSpec.min, dayOfMonthSpec.max)
+			for _, last := range lasts {
+				lastDays.Add(days - last.Offset)
+			}
+			lastItems := compressValues(lastDays, dayOfMonthSpec, NormalizeOptions{})
+			if len(lastItems) == 0 {
+				continue
+			}
+			terms = append(terms, dayTerm{List{Items: lastItems}.String(), month, ""})
+		}
+	}
+	return terms, nil
+}
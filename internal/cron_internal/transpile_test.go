@@ -0,0 +1,69 @@
+package cron_internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTranspileCron(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from, to   *Dialect
+		want       []string
+		wantCode   string
+	}{
+		{"Quartz to Kubernetes", "0 30 9 ? * 2-6", Quartz, Kubernetes, []string{"30 9 * * 1-5"}, ""},
+		{"Quartz names are kept", "0 30 9 ? * MON-FRI", Quartz, Kubernetes, []string{"30 9 * * MON-FRI"}, ""},
+		{"Kubernetes to Quartz", "*/15 9-17 * * 1-5", Kubernetes, Quartz, []string{"0 */15 9-17 ? * 2-6"}, ""},
+		{"Standard to EventBridge", "0 12 1 * *", Standard, EventBridge, []string{"0 12 1 * ? *"}, ""},
+		{"EventBridge to Vixie", "0 12 ? * 1 *", EventBridge, Vixie, []string{"0 12 * * 0"}, ""},
+		{"Sunday as 7 wraps", "0 0 * * 5-7", Vixie, Quartz, []string{"0 0 0 ? * 1,6,7"}, ""},
+		{"Sunday as 7 for Kubernetes", "0 0 * * 7", Vixie, Kubernetes, []string{"0 0 * * 0"}, ""},
+		{"Nth weekday", "0 0 9 ? * 6#3", Quartz, Standard, []string{"0 9 * * 5#3"}, ""},
+		{"Last weekday becomes Saturday", "0 0 9 ? * L", Quartz, GitHubActions, []string{"0 9 * * 6"}, ""},
+		{"Both day fields restricted", "0 0 1 * 1", Standard, Quartz, []string{"0 0 0 1 * ?", "0 0 0 ? * 2"}, ""},
+		{"Macro kept", "@daily", Standard, Kubernetes, []string{"@daily"}, ""},
+		{"Time zone kept", "CRON_TZ=Europe/Berlin 0 9 * * 1", Standard, Quartz, []string{"CRON_TZ=Europe/Berlin 0 0 9 ? * 2"}, ""},
+		{"Macro expanded", "@weekly", Standard, Quartz, []string{"0 0 0 ? * SUN"}, ""},
+		{"Macro kept in Jenkins", "@daily", Jenkins, Jenkins, []string{"@daily"}, ""},
+		{"Hashed macro to Kubernetes", "@daily", Jenkins, Kubernetes, nil, CodeUnsupported},
+		{"Hashed midnight to Standard", "@midnight", Jenkins, Standard, []string{"H H(0-2) * * *"}, ""},
+		{"Hashed macro to Vixie", "@weekly", Jenkins, Vixie, nil, CodeUnsupported},
+		{"Macro to Jenkins", "@daily", Standard, Jenkins, []string{"0 0 * * *"}, ""},
+		{"Kubernetes macro to Jenkins", "@hourly", Kubernetes, Jenkins, []string{"0 * * * *"}, ""},
+		{"Last day split by month length", "0 0 L 3-6 *", Standard, Vixie, []string{"0 0 31 3,5 *", "0 0 30 4,6 *"}, ""},
+		{"Last day with other days", "0 0 1,L 1,4 *", Standard, Vixie, []string{"0 0 1 1,4 *", "0 0 31 1 *", "0 0 30 4 *"}, ""},
+		{"Last day in February", "0 0 L * *", Standard, Vixie, nil, CodeUnsupported},
+		{"Days before month end split by month length", "0 0 L-1 3-6 *", Standard, Vixie, []string{"0 0 30 3,5 *", "0 0 29 4,6 *"}, ""},
+		{"Last Friday", "0 0 * * 5L", Standard, Quartz, []string{"0 0 0 ? * 6L"}, ""},
+		{"Last Friday unsupported", "0 0 * * 5L", Standard, Kubernetes, nil, CodeUnsupported},
+		{"Last weekday unsupported", "0 0 LW * *", Standard, Vixie, nil, CodeUnsupported},
+		{"Nearest weekday", "0 0 15W * *", Standard, Kubernetes, nil, CodeUnsupported},
+		{"Nth weekday unsupported", "0 0 * * 1#2", Standard, GitHubActions, nil, CodeUnsupported},
+		{"Seconds dropped", "0 30 9 * * ?", Quartz, Vixie, []string{"30 9 * * *"}, ""},
+		{"Seconds other than 0", "30 0 9 * * ?", Quartz, Vixie, nil, CodeUnsupported},
+		{"Year unsupported", "0 0 12 1 1 ? 2030", Quartz, Kubernetes, nil, CodeUnsupported},
+		{"Reboot unsupported", "@reboot", Standard, Quartz, nil, CodeUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TranspileCron(tt.expression, tt.from, tt.to)
+			if tt.wantCode != "" {
+				var cronErr *ValidationError
+				if !errors.As(err, &cronErr) || cronErr.Code != tt.wantCode {
+					t.Fatalf("TranspileCron() = %v, %v, want code %s", got, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TranspileCron() error = %v", err)
+			}
+			if strings.Join(got, " | ") != strings.Join(tt.want, " | ") {
+				t.Errorf("TranspileCron() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,3 +7,79 @@ func ValidateCron(expression string) error {
 	}
 	return cronExp.Validate()
 }
+
+// ValidateCronWithMode parses and validates an expression using the layout
+// selected by mode.
+func ValidateCronWithMode(expression string, mode ParseMode) error {
+	return ValidateCronDialect(expression, DialectForMode(mode))
+}
+
+// ValidateCronDialect parses and validates an expression written for the
+// given dialect.
+func ValidateCronDialect(expression string, d *Dialect) error {
+	cronExp, err := ParseCronDialect(expression, d)
+	if err != nil {
+		return err
+	}
+	return cronExp.Validate()
+}
+
+// DescribeCron parses and validates an expression and describes it in the
+// given locale.
+func DescribeCron(expression, locale string) (string, error) {
+	cronExp, err := ParseCron(expression)
+	if err != nil {
+		return "", err
+	}
+	if err := cronExp.Validate(); err != nil {
+		return "", err
+	}
+	return cronExp.DescribeIn(locale), nil
+}
+
+// NormalizeCron parses and validates an expression and returns its
+// canonical form.
+func NormalizeCron(expression string, opts NormalizeOptions) (string, error) {
+	cronExp, err := ParseCron(expression)
+	if err != nil {
+		return "", err
+	}
+	if err := cronExp.Validate(); err != nil {
+		return "", err
+	}
+	return cronExp.Normalize(opts).String(), nil
+}
+
+// TranspileCron parses and validates an expression written for one dialect
+// and rewrites it for another. See Transpile.
+func TranspileCron(expression string, from, to *Dialect) ([]string, error) {
+	cronExp, err := ParseCronDialect(expression, from)
+	if err != nil {
+		return nil, err
+	}
+	if err := cronExp.Validate(); err != nil {
+		return nil, err
+	}
+	transpiled, err := Transpile(cronExp, to)
+	if err != nil {
+		return nil, err
+	}
+	expressions := make([]string, len(transpiled))
+	for i, t := range transpiled {
+		expressions[i] = t.String()
+	}
+	return expressions, nil
+}
+
+// ResolveCron parses and validates an expression written for the given
+// dialect and returns it with every H resolved for seed. See Resolve.
+func ResolveCron(expression string, d *Dialect, seed string) (string, error) {
+	cronExp, err := ParseCronDialect(expression, d)
+	if err != nil {
+		return "", err
+	}
+	if err := cronExp.Validate(); err != nil {
+		return "", err
+	}
+	return cronExp.Resolve(seed).String(), nil
+}
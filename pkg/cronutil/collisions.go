@@ -0,0 +1,231 @@
+package cronutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+// MaxCollisionRuns is the safety limit on the runs of all jobs together
+// that AnalyzeCollisions looks at.
+const MaxCollisionRuns = 1_000_000
+
+// Job is a named schedule analyzed by AnalyzeCollisions.
+type Job struct {
+	Name       string
+	Expression string
+	// Duration is how long each run lasts. A run without one only counts
+	// at the instant it starts.
+	Duration time.Duration
+}
+
+// CollisionOptions configures AnalyzeCollisions.
+type CollisionOptions struct {
+	// Dialect is the dialect the expressions are written for, by default
+	// cron_internal.Standard.
+	Dialect *cron_internal.Dialect
+	// Location is the time zone the expressions run in unless they have a
+	// CRON_TZ= or TZ= prefix. A nil Location means From's location.
+	Location *time.Location
+	// From and Horizon select the runs analyzed: those starting after From
+	// and within Horizon of it. From defaults to now.
+	From    time.Time
+	Horizon time.Duration
+	// Threshold is the number of jobs that may start or run together
+	// without being reported, by default 1.
+	Threshold int
+	// Windows is the number of worst windows reported, by default 10.
+	Windows int
+}
+
+// CollisionWindow is a stretch of time during which more jobs than the
+// threshold run together.
+type CollisionWindow struct {
+	Start time.Time `json:"start"`
+	// End is when the first of the jobs finishes or another starts. It
+	// equals Start when none of the jobs has a duration.
+	End         time.Time `json:"end"`
+	Concurrency int       `json:"concurrency"`
+	// Starts is how many of the jobs start at Start.
+	Starts int `json:"starts"`
+	// Jobs names the jobs running, once per run, so a job overlapping its
+	// own previous run is listed twice.
+	Jobs []string `json:"jobs"`
+}
+
+// CollisionReport is the result of AnalyzeCollisions.
+type CollisionReport struct {
+	PeakConcurrency int       `json:"peak_concurrency"`
+	PeakAt          time.Time `json:"peak_at"`
+	PeakStarts      int       `json:"peak_starts"`
+	PeakStartsAt    time.Time `json:"peak_starts_at"`
+	// Collisions counts the windows with more jobs than the threshold, and
+	// Windows lists the worst of them: the most concurrent first, then the
+	// most starts, then the earliest.
+	Collisions int               `json:"collisions"`
+	Windows    []CollisionWindow `json:"windows"`
+}
+
+// jobRun is a run of the job at index job.
+type jobRun struct {
+	start, end time.Time
+	job        int
+}
+
+// AnalyzeCollisions finds when the given jobs start or run at the same
+// time, e.g. a stampede of jobs at the top of the hour, and reports the
+// peak concurrency and the worst windows.
+func AnalyzeCollisions(jobs []Job, opts CollisionOptions) (*CollisionReport, error) {
+	if opts.Horizon <= 0 {
+		return nil, errors.New("the horizon must be positive")
+	}
+	if opts.From.IsZero() {
+		opts.From = SystemClock.Now()
+	}
+	if opts.Dialect == nil {
+		opts.Dialect = cron_internal.Standard
+	}
+	if opts.Threshold < 1 {
+		opts.Threshold = 1
+	}
+	if opts.Windows < 1 {
+		opts.Windows = 10
+	}
+
+	if err := checkJobs(jobs); err != nil {
+		return nil, err
+	}
+	var runs []jobRun
+	for i, job := range jobs {
+		it, err := Iterate(job.Expression, opts.Dialect, Query{From: opts.From, To: opts.From.Add(opts.Horizon), Location: opts.Location})
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		for it.Next() {
+			if len(runs) == MaxCollisionRuns {
+				return nil, fmt.Errorf("the jobs run more than %d times within the horizon; use a shorter one", MaxCollisionRuns)
+			}
+			runs = append(runs, jobRun{it.Time(), it.Time().Add(job.Duration), i})
+		}
+	}
+
+	// starts and ends index runs in order of their start and end. Runs
+	// with a duration are active from their start until their end, and
+	// runs without one only at their start.
+	starts := make([]int, len(runs))
+	var ends []int
+	for i, r := range runs {
+		starts[i] = i
+		if r.end.After(r.start) {
+			ends = append(ends, i)
+		}
+	}
+	sort.SliceStable(starts, func(i, j int) bool { return runs[starts[i]].start.Before(runs[starts[j]].start) })
+	sort.SliceStable(ends, func(i, j int) bool { return runs[ends[i]].end.Before(runs[ends[j]].end) })
+	instants := collisionInstants(runs, starts, ends)
+
+	report := &CollisionReport{}
+	active := make(map[int]bool)
+	s, e := 0, 0
+	for k, t := range instants {
+		for ; e < len(ends) && !runs[ends[e]].end.After(t); e++ {
+			delete(active, ends[e])
+		}
+		var instant []int
+		first := s
+		for ; s < len(starts) && runs[starts[s]].start.Equal(t); s++ {
+			if runs[starts[s]].end.After(t) {
+				active[starts[s]] = true
+			} else {
+				instant = append(instant, starts[s])
+			}
+		}
+		startCount := s - first
+		concurrency := len(active) + len(instant)
+
+		if concurrency > report.PeakConcurrency {
+			report.PeakConcurrency, report.PeakAt = concurrency, t
+		}
+		if startCount > report.PeakStarts {
+			report.PeakStarts, report.PeakStartsAt = startCount, t
+		}
+		if concurrency <= opts.Threshold {
+			continue
+		}
+		report.Collisions++
+		window := CollisionWindow{Start: t, End: t, Concurrency: concurrency, Starts: startCount}
+		if len(active) > 0 && k+1 < len(instants) {
+			window.End = instants[k+1]
+		}
+		if n := len(report.Windows); n == opts.Windows && !worseWindow(report.Windows[n-1], window) {
+			continue
+		}
+		for r := range active {
+			window.Jobs = append(window.Jobs, jobs[runs[r].job].Name)
+		}
+		for _, r := range instant {
+			window.Jobs = append(window.Jobs, jobs[runs[r].job].Name)
+		}
+		sort.Strings(window.Jobs)
+		report.Windows = append(report.Windows, window)
+		sort.SliceStable(report.Windows, func(i, j int) bool { return worseWindow(report.Windows[j], report.Windows[i]) })
+		if len(report.Windows) > opts.Windows {
+			report.Windows = report.Windows[:opts.Windows]
+		}
+	}
+	return report, nil
+}
+
+// checkJobs reports a job without a name or with a name used before, or
+// with a negative duration.
+func checkJobs(jobs []Job) error {
+	names := make(map[string]bool)
+	for i, job := range jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("duplicate job name %q", job.Name)
+		}
+		names[job.Name] = true
+		if job.Duration < 0 {
+			return fmt.Errorf("job %q: the duration must not be negative", job.Name)
+		}
+	}
+	return nil
+}
+
+// collisionInstants returns the distinct times at which a run starts or
+// ends, in order. Concurrency only changes at these times.
+func collisionInstants(runs []jobRun, starts, ends []int) []time.Time {
+	var instants []time.Time
+	s, e := 0, 0
+	for s < len(starts) || e < len(ends) {
+		var t time.Time
+		if e == len(ends) || (s < len(starts) && runs[starts[s]].start.Before(runs[ends[e]].end)) {
+			t = runs[starts[s]].start
+			s++
+		} else {
+			t = runs[ends[e]].end
+			e++
+		}
+		if len(instants) == 0 || t.After(instants[len(instants)-1]) {
+			instants = append(instants, t)
+		}
+	}
+	return instants
+}
+
+// worseWindow reports whether b is a worse collision than a.
+func worseWindow(a, b CollisionWindow) bool {
+	if a.Concurrency != b.Concurrency {
+		return b.Concurrency > a.Concurrency
+	}
+	if a.Starts != b.Starts {
+		return b.Starts > a.Starts
+	}
+	return b.Start.Before(a.Start)
+}
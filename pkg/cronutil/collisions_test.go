@@ -0,0 +1,90 @@
+package cronutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeCollisions(t *testing.T) {
+	jobs := []Job{
+		{"backup", "0 * * * *", 20 * time.Minute},
+		{"report", "0 */2 * * *", 10 * time.Minute},
+		{"cleanup", "*/30 * * * *", 0},
+		{"sync", "15 * * * *", 30 * time.Minute},
+	}
+	from := time.Date(2024, 3, 3, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		threshold      int
+		windows        int
+		wantCollisions int
+		want           []string
+	}{
+		{"Default threshold", 0, 3, 6, []string{
+			"00:00-00:10 3/3 backup,cleanup,report",
+			"01:00-01:15 2/2 backup,cleanup",
+			"00:15-00:20 2/1 backup,sync",
+		}},
+		{"Higher threshold", 2, 0, 1, []string{
+			"00:00-00:10 3/3 backup,cleanup,report",
+		}},
+		{"Nothing above the threshold", 3, 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := AnalyzeCollisions(jobs, CollisionOptions{From: from, Horizon: 2 * time.Hour, Threshold: tt.threshold, Windows: tt.windows})
+			if err != nil {
+				t.Fatalf("AnalyzeCollisions() error = %v", err)
+			}
+			if report.PeakConcurrency != 3 || report.PeakStarts != 3 || report.PeakAt.Format("15:04") != "00:00" {
+				t.Errorf("AnalyzeCollisions() peak = %d starts %d at %s, want 3 and 3 at 00:00", report.PeakConcurrency, report.PeakStarts, report.PeakAt)
+			}
+			if report.Collisions != tt.wantCollisions {
+				t.Errorf("AnalyzeCollisions() collisions = %d, want %d", report.Collisions, tt.wantCollisions)
+			}
+			var got []string
+			for _, w := range report.Windows {
+				got = append(got, fmt.Sprintf("%s-%s %d/%d %s", w.Start.Format("15:04"), w.End.Format("15:04"), w.Concurrency, w.Starts, strings.Join(w.Jobs, ",")))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("AnalyzeCollisions() windows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCollisionsSelfOverlap(t *testing.T) {
+	from := time.Date(2024, 3, 3, 23, 59, 0, 0, time.UTC)
+	report, err := AnalyzeCollisions([]Job{{"slow", "*/10 * * * *", 25 * time.Minute}}, CollisionOptions{From: from, Horizon: time.Hour, Windows: 1})
+	if err != nil {
+		t.Fatalf("AnalyzeCollisions() error = %v", err)
+	}
+	if report.PeakConcurrency != 3 || len(report.Windows) != 1 || strings.Join(report.Windows[0].Jobs, ",") != "slow,slow,slow" {
+		t.Errorf("AnalyzeCollisions() = %+v, want three overlapping runs of slow", report)
+	}
+}
+
+func TestAnalyzeCollisionsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []Job
+		want string
+	}{
+		{"No name", []Job{{"", "* * * * *", 0}}, "no name"},
+		{"Duplicate name", []Job{{"a", "* * * * *", 0}, {"a", "0 * * * *", 0}}, "duplicate"},
+		{"Invalid expression", []Job{{"a", "61 * * * *", 0}}, `job "a"`},
+		{"Negative duration", []Job{{"a", "* * * * *", -time.Minute}}, "negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AnalyzeCollisions(tt.jobs, CollisionOptions{Horizon: time.Hour})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("AnalyzeCollisions() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,121 @@
 package cronutil
 
-import "time"
-import "github.com/robfig/cron/v3"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+// ErrNoRunTimes is returned for valid expressions that have no scheduled run
+// times, such as @reboot or a date that never occurs.
+var ErrNoRunTimes = errors.New("expression has no scheduled run times")
 
 func GetNextRunTimes(expression string, count int) ([]time.Time, error) {
-	schedule, err := cron.ParseStandard(expression)
+	return GetNextRunTimesWithMode(expression, cron_internal.ModeStandard, count)
+}
+
+// GetNextRunTimesWithMode is GetNextRunTimes for expressions in the layout
+// selected by mode, e.g. 6 or 7 field Quartz expressions.
+func GetNextRunTimesWithMode(expression string, mode cron_internal.ParseMode, count int) ([]time.Time, error) {
+	return GetNextRunTimesWithDialect(expression, cron_internal.DialectForMode(mode), count)
+}
+
+// GetNextRunTimesWithDialect is GetNextRunTimes for expressions written for
+// the given dialect, e.g. cron_internal.Quartz.
+func GetNextRunTimesWithDialect(expression string, dialect *cron_internal.Dialect, count int) ([]time.Time, error) {
+	return GetNextRunTimesIn(expression, dialect, nil, count)
+}
+
+// GetNextRunTimesIn is GetNextRunTimesWithDialect for an expression run in
+// loc, e.g. a user's time zone. A CRON_TZ= or TZ= prefix in the expression
+// takes precedence over loc, and a nil loc means the server's local time
+// zone. The times are returned in the zone the expression runs in.
+func GetNextRunTimesIn(expression string, dialect *cron_internal.Dialect, loc *time.Location, count int) ([]time.Time, error) {
+	return GetNextRunTimesWithClock(expression, dialect, loc, SystemClock, count)
+}
+
+// GetNextRunTimesWithClock is GetNextRunTimesIn for the runs after the
+// time told by clock rather than after time.Now().
+func GetNextRunTimesWithClock(expression string, dialect *cron_internal.Dialect, loc *time.Location, clock Clock, count int) ([]time.Time, error) {
+	times, _, err := Occurrences(expression, dialect, Query{Count: count, Location: loc, Clock: clock})
 	if err != nil {
 		return nil, err
 	}
+	if len(times) == 0 {
+		return nil, ErrNoRunTimes
+	}
+	return times, nil
+}
+
+// MaxOccurrences is the safety limit on the run times returned by a single
+// Occurrences query.
+const MaxOccurrences = 1000
+
+// Direction selects whether Occurrences walks forward or backward in time.
+type Direction int
 
-	var times []time.Time
-	now := time.Now()
-	next := schedule.Next(now)
+const (
+	// Forward lists the next runs after the reference time.
+	Forward Direction = iota
+	// Backward lists the previous runs before the reference time, most
+	// recent first.
+	Backward
+)
 
-	for i := 0; i < 5; i++ {
-		times = append(times, next)
-		next = schedule.Next(next)
+// ParseDirection parses "forward" or "backward". An empty string is
+// Forward.
+func ParseDirection(s string) (Direction, error) {
+	switch s {
+	case "", "forward":
+		return Forward, nil
+	case "backward":
+		return Backward, nil
 	}
+	return Forward, fmt.Errorf("unknown direction %q, expected forward or backward", s)
+}
 
-	return times, nil
+// Query selects the run times returned by Occurrences. At least one of To
+// and Count must be set; with both, the query stops at whichever comes
+// first.
+type Query struct {
+	// From is the reference time, defaulting to the time told by Clock.
+	// Runs at From itself are not included.
+	From time.Time
+	// To ends the window, inclusively. It must lie after From, or before it
+	// when walking Backward.
+	To time.Time
+	// Count is the number of runs to return, at most MaxOccurrences.
+	Count     int
+	Direction Direction
+	// Location is the time zone the expression runs in unless it has a
+	// CRON_TZ= or TZ= prefix. A nil Location means From's location.
+	Location *time.Location
+	// Clock tells the time when From is zero. A nil Clock is SystemClock.
+	Clock Clock
+}
+
+// Occurrences returns the run times of an expression selected by q,
+// nearest to q.From first, in the zone the expression runs in. A window
+// holding more than MaxOccurrences runs returns the first MaxOccurrences
+// with truncated set.
+func Occurrences(expression string, dialect *cron_internal.Dialect, q Query) (times []time.Time, truncated bool, err error) {
+	if q.Count < 0 || q.Count > MaxOccurrences {
+		return nil, false, fmt.Errorf("count must be between 1 and %d", MaxOccurrences)
+	}
+	if q.Count == 0 && q.To.IsZero() {
+		return nil, false, errors.New("either a count or an end time is required")
+	}
+	it, err := Iterate(expression, dialect, q)
+	if err != nil {
+		return nil, false, err
+	}
+	for it.Next() {
+		if len(times) == MaxOccurrences {
+			return times, true, nil
+		}
+		times = append(times, it.Time())
+	}
+	return times, false, nil
 }
@@ -0,0 +1,106 @@
+package cronutil
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// ExportICS returns an iCalendar (RFC 5545) calendar with an event at each
+// of the next count run times of the expression, so that a schedule can be
+// subscribed to in a calendar app. Event UIDs are derived from the
+// expression and run time, so refreshing a subscription does not duplicate
+// events. The summary defaults to the schedule's description.
+func ExportICS(expression string, dialect *cron_internal.Dialect, count int, summary string) (string, error) {
+	return ExportICSWithClock(expression, dialect, SystemClock, count, summary)
+}
+
+// ExportICSWithClock is ExportICS for the runs after the time told by
+// clock, which also stamps the events.
+func ExportICSWithClock(expression string, dialect *cron_internal.Dialect, clock Clock, count int, summary string) (string, error) {
+	schedule, err := cron_internal.ParseCronDialect(expression, dialect)
+	if err != nil {
+		return "", err
+	}
+	if err := schedule.Validate(); err != nil {
+		return "", err
+	}
+	if summary == "" {
+		summary = schedule.Describe()
+	}
+
+	now := clock.Now()
+	times := nextRunTimes(schedule, now, count)
+	if len(times) == 0 {
+		return "", ErrNoRunTimes
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(dialect.Name + " " + expression))
+	stamp := now.UTC().Format(icsTimeFormat)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//crontalk//cron schedule//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + escapeICS(summary),
+	}
+	for _, t := range times {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%x-%d@crontalk", h.Sum64(), t.Unix()),
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+t.UTC().Format(icsTimeFormat),
+			"SUMMARY:"+escapeICS(summary),
+			"DESCRIPTION:"+escapeICS("cron: "+expression),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICS(line))
+		b.WriteString("\r\n")
+	}
+	return b.String(), nil
+}
+
+// nextRunTimes returns up to count run times after from.
+func nextRunTimes(schedule *cron_internal.Expression, from time.Time, count int) []time.Time {
+	var times []time.Time
+	for next := schedule.Next(from); !next.IsZero() && len(times) < count; next = schedule.Next(next) {
+		times = append(times, next)
+	}
+	return times
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escapeICS(text string) string {
+	return icsEscaper.Replace(text)
+}
+
+// foldICS splits a content line into lines of at most 75 octets, each
+// continuation starting with a space, without splitting UTF-8 sequences.
+func foldICS(line string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package cronutil
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+// Clock tells the current time, so that callers can pin "now", e.g. in
+// tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// FixedClock is a Clock that always tells the same time.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+// Iterator walks the run times of an expression one at a time, computing
+// each only when asked for, so that long or unbounded walks do not build a
+// slice. Use it like bufio.Scanner:
+//
+//	it, err := cronutil.Iterate(expression, dialect, cronutil.Query{From: from})
+//	for it.Next() {
+//		t := it.Time()
+//		...
+//	}
+type Iterator struct {
+	step     func(time.Time) time.Time
+	t        time.Time
+	to       time.Time
+	backward bool
+	count    int
+	n        int
+	done     bool
+}
+
+// Iterate returns an Iterator over the run times of an expression selected
+// by q. Unlike Occurrences it does not require To or Count, or limit the
+// number of runs; without either it walks until Stop is called or the
+// schedule has no more runs.
+func Iterate(expression string, dialect *cron_internal.Dialect, q Query) (*Iterator, error) {
+	schedule, err := cron_internal.ParseCronDialect(expression, dialect)
+	if err != nil {
+		return nil, err
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	if q.Count < 0 {
+		return nil, errors.New("count must not be negative")
+	}
+	if schedule.Location == nil && q.Location != nil {
+		schedule.Location = q.Location
+	}
+	from := q.From
+	if from.IsZero() {
+		clock := q.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		from = clock.Now()
+	}
+
+	it := &Iterator{step: schedule.Next, t: from, to: q.To, count: q.Count}
+	switch q.Direction {
+	case Forward:
+		if !q.To.IsZero() && !q.To.After(from) {
+			return nil, errors.New("the end time must be after the start time")
+		}
+	case Backward:
+		if !q.To.IsZero() && !q.To.Before(from) {
+			return nil, errors.New("the end time must be before the start time when walking backward")
+		}
+		it.step, it.backward = schedule.Prev, true
+	default:
+		return nil, fmt.Errorf("unknown direction %d", q.Direction)
+	}
+	return it, nil
+}
+
+// Next advances to the next run time, reporting false once there are none
+// left in the query or Stop has been called.
+func (it *Iterator) Next() bool {
+	if it.done || (it.count > 0 && it.n == it.count) {
+		return false
+	}
+	t := it.step(it.t)
+	if t.IsZero() || (!it.to.IsZero() && (it.backward && t.Before(it.to) || !it.backward && t.After(it.to))) {
+		it.done = true
+		return false
+	}
+	it.t = t
+	it.n++
+	return true
+}
+
+// Time returns the run time reached by the last call to Next.
+func (it *Iterator) Time() time.Time { return it.t }
+
+// Stop ends the walk early; later calls to Next return false.
+func (it *Iterator) Stop() { it.done = true }
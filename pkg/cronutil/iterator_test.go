@@ -0,0 +1,117 @@
+package cronutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+func TestIterate(t *testing.T) {
+	now := FixedClock(time.Date(2024, 3, 10, 10, 7, 0, 0, time.UTC))
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		query      Query
+		want       []string
+	}{
+		{"Count from the clock", "*/15 * * * *", Query{Count: 2, Clock: now}, []string{"2024-03-10T10:15:00Z", "2024-03-10T10:30:00Z"}},
+		{"Backward from the clock", "*/15 * * * *", Query{Count: 2, Direction: Backward, Clock: now}, []string{"2024-03-10T10:00:00Z", "2024-03-10T09:45:00Z"}},
+		{"Window", "0 9 * * 1-5", Query{From: from, To: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)}, []string{"2024-03-01T09:00:00Z", "2024-03-04T09:00:00Z", "2024-03-05T09:00:00Z"}},
+		{"Window backward", "0 9 * * 1-5", Query{From: from, To: time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC), Direction: Backward}, []string{"2024-02-29T09:00:00Z", "2024-02-28T09:00:00Z", "2024-02-27T09:00:00Z"}},
+		{"Count within a window", "0 9 * * *", Query{From: from, To: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Count: 1}, []string{"2024-03-01T09:00:00Z"}},
+		{"Location", "0 9 * * *", Query{Count: 1, Clock: now, Location: time.FixedZone("", 2*60*60)}, []string{"2024-03-11T09:00:00+02:00"}},
+		{"Time zone prefix wins", "CRON_TZ=UTC 0 9 * * *", Query{Count: 1, Clock: now, Location: time.FixedZone("", 2*60*60)}, []string{"2024-03-11T09:00:00Z"}},
+		{"Never fires", "0 0 30 2 *", Query{Clock: now}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := Iterate(tt.expression, cron_internal.Standard, tt.query)
+			if err != nil {
+				t.Fatalf("Iterate() error = %v", err)
+			}
+			var got []string
+			for it.Next() {
+				got = append(got, it.Time().Format(time.RFC3339))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Iterate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIterateStop(t *testing.T) {
+	it, err := Iterate("* * * * * ?", cron_internal.Quartz, Query{Clock: FixedClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	n := 0
+	for it.Next() {
+		if n++; n == 100_000 {
+			it.Stop()
+		}
+	}
+	if n != 100_000 || it.Next() {
+		t.Errorf("Next() continued after Stop, %d runs", n)
+	}
+	if want := time.Date(2024, 1, 2, 3, 46, 40, 0, time.UTC); !it.Time().Equal(want) {
+		t.Errorf("Time() = %s, want %s", it.Time(), want)
+	}
+}
+
+func TestIterateErrors(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		query      Query
+		want       string
+	}{
+		{"Invalid expression", "61 * * * *", Query{}, "minute"},
+		{"Window ends before it starts", "* * * * *", Query{From: from, To: from.Add(-time.Hour)}, "after the start time"},
+		{"Window backward ends after it starts", "* * * * *", Query{From: from, To: from.Add(time.Hour), Direction: Backward}, "before the start time"},
+		{"Negative count", "* * * * *", Query{Count: -1}, "negative"},
+		{"Unknown direction", "* * * * *", Query{Direction: 2}, "unknown direction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Iterate(tt.expression, cron_internal.Standard, tt.query); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Iterate() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	times, truncated, err := Occurrences("* * * * *", cron_internal.Standard, Query{From: from, To: from.AddDate(0, 0, 1)})
+	if err != nil || len(times) != MaxOccurrences || !truncated {
+		t.Errorf("Occurrences() = %d times, truncated %v, error %v; want %d, true", len(times), truncated, err, MaxOccurrences)
+	}
+	times, truncated, err = Occurrences("0 * * * *", cron_internal.Standard, Query{From: from, To: from.AddDate(0, 0, 1)})
+	if err != nil || len(times) != 24 || truncated {
+		t.Errorf("Occurrences() = %d times, truncated %v, error %v; want 24, false", len(times), truncated, err)
+	}
+	if _, _, err := Occurrences("* * * * *", cron_internal.Standard, Query{From: from}); err == nil {
+		t.Error("Occurrences() without a count or end time succeeded")
+	}
+	if _, _, err := Occurrences("* * * * *", cron_internal.Standard, Query{Count: MaxOccurrences + 1}); err == nil {
+		t.Error("Occurrences() above MaxOccurrences succeeded")
+	}
+}
+
+func TestGetNextRunTimesWithClock(t *testing.T) {
+	clock := FixedClock(time.Date(2024, 3, 10, 10, 7, 0, 0, time.UTC))
+	times, err := GetNextRunTimesWithClock("0 * * * *", cron_internal.Standard, time.UTC, clock, 3)
+	if err != nil || len(times) != 3 || times[0].Hour() != 11 {
+		t.Errorf("GetNextRunTimesWithClock() = %v, %v; want 3 hourly times from 11:00", times, err)
+	}
+	if _, err := GetNextRunTimesWithClock("@reboot", cron_internal.Standard, nil, clock, 3); err != ErrNoRunTimes {
+		t.Errorf("GetNextRunTimesWithClock(@reboot) error = %v, want ErrNoRunTimes", err)
+	}
+}
@@ -0,0 +1,249 @@
+package cronutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+// StaggerJob is a Job that Stagger may move by up to Tolerance.
+type StaggerJob struct {
+	Job
+	// Tolerance is how much later each run may start, e.g. 59 minutes for
+	// any minute of its hour. Runs only move within their hour, so a job
+	// also cannot move past the last minute of the hour.
+	Tolerance time.Duration
+}
+
+// StaggerOptions configures Stagger.
+type StaggerOptions struct {
+	// Dialect is the dialect the expressions are written for, by default
+	// cron_internal.Standard.
+	Dialect *cron_internal.Dialect
+	// Location is the time zone the expressions run in unless they have a
+	// CRON_TZ= or TZ= prefix. A nil Location means From's location.
+	Location *time.Location
+	// From and Horizon select the runs balanced: those starting after From
+	// and within Horizon of it. From defaults to now and Horizon to a week.
+	From    time.Time
+	Horizon time.Duration
+}
+
+// StaggerSuggestion is the expression Stagger proposes for a job.
+type StaggerSuggestion struct {
+	Name       string `json:"name"`
+	Expression string `json:"cron_expression"`
+	// Suggested is Expression with its runs moved OffsetMinutes later, or
+	// Expression itself when the job is best left where it is.
+	Suggested     string `json:"suggested_expression"`
+	OffsetMinutes int    `json:"offset_minutes"`
+}
+
+// ConcurrencyProfile is how many jobs run together, measured per minute.
+type ConcurrencyProfile struct {
+	PeakConcurrency int `json:"peak_concurrency"`
+	PeakStarts      int `json:"peak_starts"`
+	// ByMinute is the most jobs running at each minute past the hour.
+	ByMinute [60]int `json:"by_minute"`
+}
+
+// StaggerPlan is the result of Stagger: a suggestion for each job, in the
+// order given, and the concurrency before and after applying them.
+type StaggerPlan struct {
+	Suggestions []StaggerSuggestion `json:"suggestions"`
+	Before      ConcurrencyProfile  `json:"before"`
+	After       ConcurrencyProfile  `json:"after"`
+}
+
+// staggered is a job being placed by Stagger. runs are the minutes after
+// From at which it starts unmoved, and each run lasts length minutes.
+type staggered struct {
+	schedule *cron_internal.Expression
+	runs     []int
+	length   int
+	slack    int
+	offset   int
+}
+
+// Stagger proposes rewritten expressions that spread the jobs' start times
+// so that fewer run at once, e.g. moving jobs that all start on the hour to
+// different minutes. Each job only moves its minute field, within its
+// hour and Tolerance, so it keeps its frequency, hours and days.
+//
+// Jobs are placed one at a time, those with the least room to move first
+// and then those with the most running time. Each goes at the offset that
+// overlaps the fewest already placed runs and, among those, the one
+// furthest from its neighbours.
+func Stagger(jobs []StaggerJob, opts StaggerOptions) (*StaggerPlan, error) {
+	if opts.Horizon < 0 {
+		return nil, errors.New("the horizon must be positive")
+	}
+	if opts.Horizon == 0 {
+		opts.Horizon = 7 * 24 * time.Hour
+	}
+	if opts.From.IsZero() {
+		opts.From = SystemClock.Now()
+	}
+	if opts.Dialect == nil {
+		opts.Dialect = cron_internal.Standard
+	}
+	plain := make([]Job, len(jobs))
+	for i, job := range jobs {
+		plain[i] = job.Job
+	}
+	if err := checkJobs(plain); err != nil {
+		return nil, err
+	}
+
+	from := opts.From.Truncate(time.Minute)
+	size, total := int(opts.Horizon/time.Minute)+60, 0
+	placed := make([]staggered, len(jobs))
+	for i, job := range jobs {
+		if job.Tolerance < 0 {
+			return nil, fmt.Errorf("job %q: the tolerance must not be negative", job.Name)
+		}
+		schedule, err := cron_internal.ParseCronDialect(job.Expression, opts.Dialect)
+		if err == nil {
+			err = schedule.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		p := staggered{schedule: schedule, length: int((job.Duration + time.Minute - 1) / time.Minute), slack: schedule.MinuteSlack()}
+		if p.length < 1 {
+			p.length = 1
+		}
+		if tolerance := int(job.Tolerance / time.Minute); tolerance < p.slack {
+			p.slack = tolerance
+		}
+		it, err := Iterate(job.Expression, opts.Dialect, Query{From: opts.From, To: opts.From.Add(opts.Horizon), Location: opts.Location})
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		for it.Next() {
+			if total++; total > MaxCollisionRuns {
+				return nil, fmt.Errorf("the jobs run more than %d times within the horizon; use a shorter one", MaxCollisionRuns)
+			}
+			p.runs = append(p.runs, int(it.Time().Sub(from)/time.Minute))
+		}
+		if end := int(opts.Horizon/time.Minute) + 60 + p.length; end > size {
+			size = end
+		}
+		placed[i] = p
+	}
+
+	load, starts := make([]int, size), make([]int, size)
+	loc := opts.From.Location()
+	if opts.Location != nil {
+		loc = opts.Location
+	}
+	for _, p := range placed {
+		p.place(load, starts, 1)
+	}
+	plan := &StaggerPlan{Before: concurrencyProfile(load, starts, from, loc)}
+	for _, p := range placed {
+		p.place(load, starts, -1)
+	}
+
+	order := make([]int, len(placed))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := placed[order[i]], placed[order[j]]
+		if a.slack != b.slack {
+			return a.slack < b.slack
+		}
+		return len(a.runs)*a.length > len(b.runs)*b.length
+	})
+	for _, i := range order {
+		p := &placed[i]
+		best, bestCost, bestGap := 0, -1, -1
+		for p.offset = 0; p.offset <= p.slack; p.offset++ {
+			cost, gap := p.cost(load, starts)
+			if bestCost < 0 || cost < bestCost || (cost == bestCost && gap > bestGap) {
+				best, bestCost, bestGap = p.offset, cost, gap
+			}
+		}
+		p.offset = best
+		p.place(load, starts, 1)
+	}
+	plan.After = concurrencyProfile(load, starts, from, loc)
+
+	for i, p := range placed {
+		shifted, err := p.schedule.ShiftMinutes(p.offset)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", jobs[i].Name, err)
+		}
+		suggestion := StaggerSuggestion{Name: jobs[i].Name, Expression: jobs[i].Expression, Suggested: jobs[i].Expression, OffsetMinutes: p.offset}
+		if p.offset > 0 {
+			suggestion.Suggested = shifted.String()
+		}
+		plan.Suggestions = append(plan.Suggestions, suggestion)
+	}
+	return plan, nil
+}
+
+// place adds delta to the load and starts of the minutes the job's runs
+// take up at its offset.
+func (p staggered) place(load, starts []int, delta int) {
+	for _, r := range p.runs {
+		start := r + p.offset
+		starts[start] += delta
+		for m := start; m < start+p.length; m++ {
+			load[m] += delta
+		}
+	}
+}
+
+// cost returns how many already placed runs the job's runs would overlap
+// or start with at its offset, and the fewest free minutes, up to an hour,
+// between any of its runs and its nearest neighbour.
+func (p staggered) cost(load, starts []int) (cost, gap int) {
+	gap = 60
+	for _, r := range p.runs {
+		start, end := r+p.offset, r+p.offset+p.length
+		cost += starts[start]
+		for m := start; m < end; m++ {
+			cost += load[m]
+		}
+		before, after := 0, 0
+		for before < gap && start-before > 0 && load[start-before-1] == 0 {
+			before++
+		}
+		for after < gap && end+after < len(load) && load[end+after] == 0 {
+			after++
+		}
+		if before < gap {
+			gap = before
+		}
+		if after < gap {
+			gap = after
+		}
+	}
+	return cost, gap
+}
+
+// concurrencyProfile summarizes the per minute load, where minute i is i
+// minutes after from.
+func concurrencyProfile(load, starts []int, from time.Time, loc *time.Location) ConcurrencyProfile {
+	var profile ConcurrencyProfile
+	for i, n := range load {
+		if starts[i] > profile.PeakStarts {
+			profile.PeakStarts = starts[i]
+		}
+		if n == 0 {
+			continue
+		}
+		if n > profile.PeakConcurrency {
+			profile.PeakConcurrency = n
+		}
+		if minute := from.Add(time.Duration(i) * time.Minute).In(loc).Minute(); n > profile.ByMinute[minute] {
+			profile.ByMinute[minute] = n
+		}
+	}
+	return profile
+}
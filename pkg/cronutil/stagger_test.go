@@ -0,0 +1,75 @@
+package cronutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStagger(t *testing.T) {
+	jobs := []StaggerJob{
+		{Job{"backup", "0 * * * *", 10 * time.Minute}, 59 * time.Minute},
+		{Job{"report", "@hourly", 10 * time.Minute}, 59 * time.Minute},
+		{Job{"sync", "0 */2 * * *", 10 * time.Minute}, 50 * time.Minute},
+		{Job{"billing", "0 * * * *", 10 * time.Minute}, 0},
+	}
+	plan, err := Stagger(jobs, StaggerOptions{From: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Horizon: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Stagger() error = %v", err)
+	}
+
+	want := []string{"15 * * * *", "45 * * * *", "30 */2 * * *", "0 * * * *"}
+	for i, s := range plan.Suggestions {
+		if s.Name != jobs[i].Name || s.Suggested != want[i] {
+			t.Errorf("Stagger() suggests %s for %s, want %s", s.Suggested, s.Name, want[i])
+		}
+	}
+	if plan.Before.PeakConcurrency != 4 || plan.Before.PeakStarts != 4 || plan.Before.ByMinute[0] != 4 {
+		t.Errorf("Stagger() before = %+v, want 4 jobs at the top of the hour", plan.Before)
+	}
+	if plan.After.PeakConcurrency != 1 || plan.After.PeakStarts != 1 {
+		t.Errorf("Stagger() after = %+v, want no overlap", plan.After)
+	}
+}
+
+func TestStaggerKeepsConstraints(t *testing.T) {
+	jobs := []StaggerJob{
+		{Job{"quarterly", "*/15 9-17 * * 1-5", 5 * time.Minute}, time.Hour},
+		{Job{"fixed", "*/15 9-17 * * 1-5", 5 * time.Minute}, 0},
+		{Job{"every", "@every 1h", 0}, time.Hour},
+		{Job{"tight", "0 9 * * *", 0}, 2 * time.Minute},
+	}
+	plan, err := Stagger(jobs, StaggerOptions{From: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Stagger() error = %v", err)
+	}
+	want := []string{"7-52/15 9-17 * * 1-5", "*/15 9-17 * * 1-5", "@every 1h", "1 9 * * *"}
+	var got []string
+	for _, s := range plan.Suggestions {
+		got = append(got, s.Suggested)
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Stagger() = %q, want %q", got, want)
+	}
+}
+
+func TestStaggerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []StaggerJob
+		want string
+	}{
+		{"Duplicate name", []StaggerJob{{Job{"a", "* * * * *", 0}, 0}, {Job{"a", "0 * * * *", 0}, 0}}, "duplicate"},
+		{"Invalid expression", []StaggerJob{{Job{"a", "61 * * * *", 0}, 0}}, `job "a"`},
+		{"Negative tolerance", []StaggerJob{{Job{"a", "0 * * * *", 0}, -time.Minute}}, "tolerance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Stagger(tt.jobs, StaggerOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Stagger() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
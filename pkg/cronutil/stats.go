@@ -0,0 +1,110 @@
+package cronutil
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+// MaxStatsRuns is the safety limit on the runs Analyze looks at. When a
+// horizon holds more, the statistics cover the runs up to the limit.
+const MaxStatsRuns = 100_000
+
+// averageMonthDays is the mean length of a Gregorian month.
+const averageMonthDays = 365.2425 / 12
+
+// StatsSummary is how often a schedule runs over a horizon. Rates are
+// averages over the whole horizon, and gaps are the time between
+// consecutive runs.
+type StatsSummary struct {
+	Runs           int     `json:"runs"`
+	RunsPerHour    float64 `json:"runs_per_hour"`
+	RunsPerDay     float64 `json:"runs_per_day"`
+	RunsPerWeek    float64 `json:"runs_per_week"`
+	RunsPerMonth   float64 `json:"runs_per_month"`
+	MinGapSeconds  float64 `json:"min_gap_seconds"`
+	MaxGapSeconds  float64 `json:"max_gap_seconds"`
+	MeanGapSeconds float64 `json:"mean_gap_seconds"`
+	// BusiestHour is the hour of the day with the most runs, the earliest
+	// on a tie, or -1 if the schedule does not run.
+	BusiestHour int `json:"busiest_hour"`
+}
+
+// Stats is the StatsSummary of a schedule with the data behind it.
+type Stats struct {
+	StatsSummary
+	// From and To are the horizon analyzed. When Truncated is set, To is
+	// the last of MaxStatsRuns runs rather than the end of the horizon.
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Truncated bool      `json:"truncated,omitempty"`
+	// ByHour counts runs by hour of the day and ByWeekday by day of the
+	// week, Sunday first, in the zone the expression runs in.
+	ByHour    [24]int `json:"by_hour"`
+	ByWeekday [7]int  `json:"by_weekday"`
+}
+
+// Analyze returns frequency statistics for the runs of an expression in
+// the horizon after from. A CRON_TZ= or TZ= prefix in the expression takes
+// precedence over loc, and a nil loc means from's location.
+func Analyze(expression string, dialect *cron_internal.Dialect, loc *time.Location, from time.Time, horizon time.Duration) (*Stats, error) {
+	if horizon <= 0 {
+		return nil, errors.New("the horizon must be positive")
+	}
+	it, err := Iterate(expression, dialect, Query{From: from, To: from.Add(horizon), Location: loc})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{From: from, To: from.Add(horizon)}
+	var first, last time.Time
+	var minGap, maxGap time.Duration
+	for it.Next() {
+		if stats.Runs == MaxStatsRuns {
+			stats.To, stats.Truncated = last, true
+			break
+		}
+		t := it.Time()
+		if stats.Runs == 0 {
+			first = t
+		} else {
+			gap := t.Sub(last)
+			if stats.Runs == 1 || gap < minGap {
+				minGap = gap
+			}
+			if gap > maxGap {
+				maxGap = gap
+			}
+		}
+		stats.ByHour[t.Hour()]++
+		stats.ByWeekday[t.Weekday()]++
+		stats.Runs++
+		last = t
+	}
+
+	hours := stats.To.Sub(stats.From).Hours()
+	runs := float64(stats.Runs)
+	stats.RunsPerHour = round(runs / hours)
+	stats.RunsPerDay = round(runs / hours * 24)
+	stats.RunsPerWeek = round(runs / hours * 7 * 24)
+	stats.RunsPerMonth = round(runs / hours * averageMonthDays * 24)
+	if stats.Runs > 1 {
+		stats.MinGapSeconds = minGap.Seconds()
+		stats.MaxGapSeconds = maxGap.Seconds()
+		stats.MeanGapSeconds = round(last.Sub(first).Seconds() / (runs - 1))
+	}
+	stats.BusiestHour = -1
+	for hour, n := range stats.ByHour {
+		if n > 0 && (stats.BusiestHour < 0 || n > stats.ByHour[stats.BusiestHour]) {
+			stats.BusiestHour = hour
+		}
+	}
+	return stats, nil
+}
+
+// round rounds x to 3 decimal places.
+func round(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
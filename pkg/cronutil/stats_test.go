@@ -0,0 +1,58 @@
+package cronutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhikvarma/crontalk/internal/cron_internal"
+)
+
+func TestAnalyze(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		horizon    time.Duration
+		want       StatsSummary
+		weekdays   [7]int
+	}{
+		{"Weekdays", "0 9 * * 1-5", 28 * 24 * time.Hour,
+			StatsSummary{20, 0.03, 0.714, 5, 21.741, 86400, 259200, 113684.211, 9},
+			[7]int{0, 4, 4, 4, 4, 4, 0}},
+		{"Every 15 minutes", "*/15 * * * *", 7 * 24 * time.Hour,
+			StatsSummary{672, 4, 96, 672, 2921.94, 900, 900, 900, 0},
+			[7]int{96, 96, 96, 96, 96, 96, 96}},
+		{"Busiest hour on a tie", "0 8,12 * * *", 7 * 24 * time.Hour,
+			StatsSummary{14, 0.083, 2, 14, 60.874, 14400, 72000, 40984.615, 8},
+			[7]int{2, 2, 2, 2, 2, 2, 2}},
+		{"Never runs", "0 0 30 2 *", 365 * 24 * time.Hour,
+			StatsSummary{BusiestHour: -1},
+			[7]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := Analyze(tt.expression, cron_internal.Standard, nil, monday, tt.horizon)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if stats.StatsSummary != tt.want {
+				t.Errorf("Analyze() = %+v, want %+v", stats.StatsSummary, tt.want)
+			}
+			if stats.ByWeekday != tt.weekdays {
+				t.Errorf("Analyze() by weekday = %v, want %v", stats.ByWeekday, tt.weekdays)
+			}
+		})
+	}
+}
+
+func TestAnalyzeTruncated(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats, err := Analyze("* * * * * ?", cron_internal.Quartz, nil, from, 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if !stats.Truncated || stats.Runs != MaxStatsRuns || stats.RunsPerHour != 3600 {
+		t.Errorf("Analyze() = %+v, want %d runs, truncated, at 3600 an hour", stats.StatsSummary, MaxStatsRuns)
+	}
+}